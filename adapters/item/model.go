@@ -1,9 +1,33 @@
 package item
 
+import (
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
 type Item struct {
 	ItemID      string  `json:"item_id"`
 	Name        string  `json:"name"`
 	Category    string  `json:"category"`
 	Description string  `json:"description"`
-	Price       float32 `json:"price"`
+	Price       float64 `json:"price"`
+}
+
+func (i *Item) ToUseCaseEntity() *entities.Item {
+	return &entities.Item{
+		ItemID:      i.ItemID,
+		Name:        i.Name,
+		Category:    entities.Category(i.Category),
+		Description: i.Description,
+		Price:       i.Price,
+	}
+}
+
+func FromUseCaseEntity(item *entities.Item) *Item {
+	return &Item{
+		ItemID:      item.ItemID,
+		Name:        item.Name,
+		Category:    string(item.Category),
+		Description: item.Description,
+		Price:       item.Price,
+	}
 }
@@ -0,0 +1,78 @@
+package kitchen
+
+import (
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+type Queue struct {
+	Orders []QueuedOrder `json:"orders"`
+	Items  []ItemTotal   `json:"items"`
+}
+
+type QueuedOrder struct {
+	OrderID        string       `json:"order_id"`
+	Status         string       `json:"status"`
+	Station        string       `json:"station,omitempty"`
+	Notes          string       `json:"notes"`
+	OrderedItems   []QueuedItem `json:"ordered_items"`
+	ReceivedAt     time.Time    `json:"received_at"`
+	ElapsedSeconds int64        `json:"elapsed_seconds"`
+	Version        int          `json:"version"`
+}
+
+type QueuedItem struct {
+	ItemID   string `json:"item_id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// ItemTotal is how many units of an item the queued orders ask for, and in how many orders
+type ItemTotal struct {
+	ItemID   string `json:"item_id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Orders   int    `json:"orders"`
+}
+
+type Claim struct {
+	Station string `json:"station"`
+	Version int    `json:"version"`
+}
+
+func QueueFromUseCaseEntity(queue *entities.KitchenQueue) *Queue {
+	orders := []QueuedOrder{}
+	for _, queued := range queue.Orders {
+		items := []QueuedItem{}
+		for _, orderedItem := range queued.Order.OrderedItems {
+			items = append(items, QueuedItem{
+				ItemID:   orderedItem.ItemID,
+				Name:     orderedItem.Name,
+				Quantity: orderedItem.Quantity,
+			})
+		}
+		orders = append(orders, QueuedOrder{
+			OrderID:        queued.Order.OrderID,
+			Status:         string(queued.Order.Status),
+			Station:        queued.Order.Station,
+			Notes:          queued.Order.Notes,
+			OrderedItems:   items,
+			ReceivedAt:     queued.ReceivedAt.UTC(),
+			ElapsedSeconds: int64(queued.Elapsed / time.Second),
+			Version:        queued.Order.Version,
+		})
+	}
+
+	totals := []ItemTotal{}
+	for _, item := range queue.Items {
+		totals = append(totals, ItemTotal{
+			ItemID:   item.ItemID,
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Orders:   item.Orders,
+		})
+	}
+
+	return &Queue{Orders: orders, Items: totals}
+}
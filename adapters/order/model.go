@@ -1,26 +1,76 @@
 package Order
 
 import (
+	"time"
+
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 )
 
+// Order is the order of requests and responses. The amounts are calculated by the server,
+// the ones sent in requests are ignored.
 type Order struct {
-	OrderID      string        `json:"order_id"`
-	ClientID     string        `json:"client_id"`
-	Status       string        `json:"status"`
-	OrderedItems []OrderedItem `json:"ordered_items"`
-	Notes        string        `json:"notes"`
-	CreatedAt    string        `json:"created_at"`
-	UpdatedAt    string        `json:"updated_at"`
+	OrderID       string        `json:"order_id"`
+	ClientID      string        `json:"client_id"`
+	Status        string        `json:"status"`
+	OrderedItems  []OrderedItem `json:"ordered_items"`
+	Notes         string        `json:"notes"`
+	SubtotalCents int64         `json:"subtotal_cents"`
+	DiscountCents int64         `json:"discount_cents"`
+	TotalCents    int64         `json:"total_cents"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Version       int           `json:"version"`
+	DeletedAt     *time.Time    `json:"deleted_at,omitempty"`
+	DeletedBy     string        `json:"deleted_by,omitempty"`
+	Station       string        `json:"station,omitempty"`
+	ClaimedAt     *time.Time    `json:"claimed_at,omitempty"`
+	// NextStatuses is empty, never left out, for the terminal statuses
+	NextStatuses []string `json:"next_statuses"`
+	// Reason explains the status change of an update, it is required to cancel the order. It is never returned.
+	Reason string `json:"reason,omitempty"`
 }
 
 type OrderedItem struct {
-	ItemID      string  `json:"item_id"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Name        string  `json:"name"`
-	Category    string  `json:"category"`
-	Description string  `json:"description"`
+	ItemID         string  `json:"item_id"`
+	Price          float64 `json:"price"`
+	Quantity       int     `json:"quantity"`
+	Name           string  `json:"name"`
+	Category       string  `json:"category"`
+	Description    string  `json:"description"`
+	LineTotalCents int64   `json:"line_total_cents"`
+}
+
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+type StatusUpdate struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Version int    `json:"version"`
+}
+
+type Cancellation struct {
+	Reason  string `json:"reason"`
+	Version int    `json:"version"`
+}
+
+type StatusChange struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason"`
+}
+
+// Event is an order event as it is sent to the order streams
+type Event struct {
+	EventID      string        `json:"event_id"`
+	Type         string        `json:"type"`
+	OccurredAt   time.Time     `json:"occurred_at"`
+	Order        *Order        `json:"order"`
+	StatusChange *StatusChange `json:"status_change,omitempty"`
 }
 
 func (o *Order) orderItemToEntity() (itemList []entities.OrderedItem) {
@@ -44,20 +94,20 @@ func (o *Order) ToUseCaseEntity() *entities.Order {
 		OrderedItems: o.orderItemToEntity(),
 		Notes:        o.Notes,
 		ClientID:     o.ClientID,
-		CreatedAt:    o.CreatedAt,
-		UpdatedAt:    o.UpdatedAt,
+		Version:      o.Version,
 	}
 }
 
 func orderItemFromEntity(orderedItems []entities.OrderedItem) (itemList []OrderedItem) {
 	for _, orderedItem := range orderedItems {
 		itemList = append(itemList, OrderedItem{
-			ItemID:      orderedItem.ItemID,
-			Price:       orderedItem.Price,
-			Quantity:    orderedItem.Quantity,
-			Name:        orderedItem.Name,
-			Category:    orderedItem.Category,
-			Description: orderedItem.Description,
+			ItemID:         orderedItem.ItemID,
+			Price:          orderedItem.Price,
+			Quantity:       orderedItem.Quantity,
+			Name:           orderedItem.Name,
+			Category:       orderedItem.Category,
+			Description:    orderedItem.Description,
+			LineTotalCents: int64(orderedItem.LineTotal),
 		})
 	}
 	return itemList
@@ -65,12 +115,82 @@ func orderItemFromEntity(orderedItems []entities.OrderedItem) (itemList []Ordere
 
 func FromUseCaseEntity(order *entities.Order) *Order {
 	return &Order{
-		OrderID:      order.OrderID,
-		Status:       string(order.Status),
-		OrderedItems: orderItemFromEntity(order.OrderedItems),
-		Notes:        order.Notes,
-		ClientID:     order.ClientID,
-		CreatedAt:    order.CreatedAt,
-		UpdatedAt:    order.UpdatedAt,
+		OrderID:       order.OrderID,
+		Status:        string(order.Status),
+		OrderedItems:  orderItemFromEntity(order.OrderedItems),
+		Notes:         order.Notes,
+		SubtotalCents: int64(order.Subtotal),
+		DiscountCents: int64(order.Discount),
+		TotalCents:    int64(order.Total),
+		ClientID:      order.ClientID,
+		CreatedAt:     order.CreatedAt.UTC(),
+		UpdatedAt:     order.UpdatedAt.UTC(),
+		Version:       order.Version,
+		DeletedAt:     utcOrNil(order.DeletedAt),
+		DeletedBy:     order.DeletedBy,
+		Station:       order.Station,
+		ClaimedAt:     utcOrNil(order.ClaimedAt),
+		NextStatuses:  nextStatusesFromEntity(order),
+	}
+}
+
+// utcOrNil returns the time in UTC, so every timestamp of the API is RFC 3339 UTC
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func nextStatusesFromEntity(order *entities.Order) []string {
+	statuses := []string{}
+	for _, status := range order.NextStatuses() {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
+
+func StatusHistoryFromEntity(order *entities.Order) []StatusChange {
+	history := []StatusChange{}
+	for _, change := range order.StatusHistory {
+		history = append(history, StatusChange{
+			From:      string(change.From),
+			To:        string(change.To),
+			ChangedAt: change.ChangedAt.UTC(),
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		})
+	}
+	return history
+}
+
+func EventFromUseCaseEntity(event *entities.OrderEvent) *Event {
+	streamed := &Event{
+		EventID:    event.EventID,
+		Type:       string(event.Type),
+		OccurredAt: event.OccurredAt.UTC(),
+		Order:      FromUseCaseEntity(&event.Data.Order),
+	}
+	if change := event.Data.StatusChange; change != nil {
+		streamed.StatusChange = &StatusChange{
+			From:      string(change.From),
+			To:        string(change.To),
+			ChangedAt: change.ChangedAt.UTC(),
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		}
+	}
+	return streamed
+}
+
+func PageFromUseCaseEntity(page *entities.OrderPage) *OrderPage {
+	orders := []*Order{}
+	for i := range page.Orders {
+		orders = append(orders, FromUseCaseEntity(&page.Orders[i]))
+	}
+	return &OrderPage{
+		Orders:     orders,
+		NextCursor: page.NextCursor,
 	}
 }
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// Subscription is a webhook subscription. The secret is only read, it is never returned.
+type Subscription struct {
+	SubscriptionID string     `json:"subscription_id"`
+	URL            string     `json:"url"`
+	EventTypes     []string   `json:"event_types"`
+	Statuses       []string   `json:"statuses"`
+	Secret         string     `json:"secret,omitempty"`
+	Active         *bool      `json:"active,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+type Delivery struct {
+	DeliveryID     string     `json:"delivery_id"`
+	SubscriptionID string     `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// ToUseCaseEntity reads a subscription request, subscriptions are active unless told otherwise
+func (s *Subscription) ToUseCaseEntity() *entities.WebhookSubscription {
+	subscription := &entities.WebhookSubscription{
+		URL:    s.URL,
+		Secret: s.Secret,
+		Active: s.Active == nil || *s.Active,
+	}
+	for _, eventType := range s.EventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, entities.EventType(eventType))
+	}
+	for _, status := range s.Statuses {
+		subscription.Statuses = append(subscription.Statuses, entities.Status(status))
+	}
+	return subscription
+}
+
+func FromUseCaseEntity(subscription *entities.WebhookSubscription) *Subscription {
+	active := subscription.Active
+	createdAt := subscription.CreatedAt.UTC()
+	updatedAt := subscription.UpdatedAt.UTC()
+	model := &Subscription{
+		SubscriptionID: subscription.SubscriptionID,
+		URL:            subscription.URL,
+		EventTypes:     []string{},
+		Statuses:       []string{},
+		Active:         &active,
+		CreatedAt:      &createdAt,
+		UpdatedAt:      &updatedAt,
+	}
+	for _, eventType := range subscription.EventTypes {
+		model.EventTypes = append(model.EventTypes, string(eventType))
+	}
+	for _, status := range subscription.Statuses {
+		model.Statuses = append(model.Statuses, string(status))
+	}
+	return model
+}
+
+func DeliveryFromUseCaseEntity(delivery *entities.WebhookDelivery) *Delivery {
+	model := &Delivery{
+		DeliveryID:     delivery.DeliveryID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.UTC(),
+		LastAttemptAt:  utcOrNil(delivery.LastAttemptAt),
+		DeliveredAt:    utcOrNil(delivery.DeliveredAt),
+	}
+	if delivery.Status == entities.PendingDeliveryStatus {
+		model.NextAttemptAt = utcOrNil(delivery.NextAttemptAt)
+	}
+	return model
+}
+
+// utcOrNil returns the time in UTC, nil for the zero time
+func utcOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"database/sql"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
+	idg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/idempotency"
+	ig "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/item"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	whg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/webhook"
+	"github.com/postech-soat2-grupo16/pedidos-api/gateways/message"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+)
+
+// Databases are the connections of the storage backend, nil when the backend does not use them
+type Databases struct {
+	DynamoDB *dynamodb.DynamoDB
+	Postgres *sql.DB
+}
+
+// gateways are the storage and queue implementations the use cases are built with
+type gateways struct {
+	orders      interfaces.OrderGatewayI
+	items       interfaces.ItemGatewayI
+	outbox      interfaces.OutboxGatewayI
+	queue       interfaces.QueueGatewayI
+	idempotency interfaces.IdempotencyGatewayI
+	webhooks    interfaces.WebhookGatewayI
+}
+
+// memoryGateways hold the data of the memory backend, shared by the router and the workers of the process
+var memoryGateways = newMemoryGateways()
+
+func newMemoryGateways() *gateways {
+	outbox := obg.NewMemoryGateway()
+	return &gateways{
+		orders:      og.NewMemoryGateway(outbox),
+		items:       ig.NewMemoryGateway(),
+		outbox:      outbox,
+		queue:       message.NewMemoryGateway(),
+		idempotency: idg.NewMemoryGateway(),
+		webhooks:    whg.NewMemoryGateway(),
+	}
+}
+
+// newGateways returns the gateways of the configured storage backend, on the configured tables and queue.
+// The memory backend ignores the connections.
+// The Postgres backend keeps the orders and their outbox in Postgres and everything else in DynamoDB.
+func newGateways(cfg *config.Config, db *Databases, queue *sqs.SQS) *gateways {
+	if cfg.StorageBackend == config.MemoryStorage {
+		return memoryGateways
+	}
+
+	tables := cfg.DynamoDB.Tables
+	g := &gateways{
+		orders:      og.NewGateway(db.DynamoDB, tables.Orders, tables.OrdersOutbox),
+		items:       ig.NewGateway(db.DynamoDB, tables.Items),
+		outbox:      obg.NewGateway(db.DynamoDB, tables.OrdersOutbox),
+		queue:       message.NewGateway(queue, cfg.Queues.OrdersURL),
+		idempotency: idg.NewGateway(db.DynamoDB, tables.IdempotencyKeys),
+		webhooks:    whg.NewGateway(db.DynamoDB, tables.WebhookSubscriptions, tables.WebhookDeliveries),
+	}
+	if cfg.StorageBackend == config.PostgresStorage {
+		g.orders = og.NewPostgresGateway(db.Postgres)
+		g.outbox = obg.NewPostgresGateway(db.Postgres)
+	}
+	return g
+}
@@ -1,47 +1,118 @@
 package api
 
 import (
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/postech-soat2-grupo16/pedidos-api/gateways/message"
+	"log"
 	"net/http"
 
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
 	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
 	"github.com/postech-soat2-grupo16/pedidos-api/external"
+	"github.com/postech-soat2-grupo16/pedidos-api/gateways/broadcast"
 	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/gateways/message"
+	whs "github.com/postech-soat2-grupo16/pedidos-api/gateways/webhook"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/idempotency"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/item"
 	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/webhook"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func SetupDB() *dynamodb.DynamoDB {
-	return external.GetDynamoDbClient()
+// orderBroadcaster sends the events of the order changes made by this process to the order streams
+var orderBroadcaster = broadcast.NewBroadcaster()
+
+// SetupDB connects to the databases of the configured storage backend.
+// The Postgres schema is migrated before the connection is returned.
+func SetupDB(cfg *config.Config) *Databases {
+	switch cfg.StorageBackend {
+	case config.MemoryStorage:
+		log.Println("Storing data in memory: STORAGE_BACKEND is memory")
+		return &Databases{}
+	case config.PostgresStorage:
+		db := &Databases{DynamoDB: external.GetDynamoDbClient(cfg), Postgres: external.GetPostgresClient(cfg)}
+		if err := og.MigratePostgres(db.Postgres); err != nil {
+			log.Fatalln("Error migrating the Postgres schema:", err)
+		}
+		return db
+	default:
+		return &Databases{DynamoDB: external.GetDynamoDbClient(cfg)}
+	}
+}
+
+// SetupQueue connects to SQS. It returns nil when the storage backend is memory, the messages are recorded in memory then.
+func SetupQueue(cfg *config.Config) *sqs.SQS {
+	if cfg.StorageBackend == config.MemoryStorage {
+		return nil
+	}
+	return external.GetSqsClient(cfg)
+}
+
+// SetupOutboxRelay builds the relay that publishes the order events saved in the outbox to the queue and to the webhooks
+func SetupOutboxRelay(cfg *config.Config, db *Databases, queue *sqs.SQS) *outbox.Relay {
+	g := newGateways(cfg, db, queue)
+	relay := outbox.NewRelay(g.outbox, g.queue)
+	relay.Webhooks = newWebhookUseCase(g)
+	return relay
+}
+
+// SetupPaymentConsumer builds the consumer of the payment results queue.
+// It returns nil when there is no queue client or no payment results queue is configured.
+func SetupPaymentConsumer(cfg *config.Config, db *Databases, queue *sqs.SQS) *controllers.PaymentConsumer {
+	queueURL := cfg.Queues.PaymentResultsURL
+	if queue == nil || queueURL == "" {
+		log.Println("Payment results consumer disabled: PAYMENT_RESULTS_QUEUE_URL is not set")
+		return nil
+	}
+
+	return controllers.NewPaymentConsumer(newOrderUseCase(newGateways(cfg, db, queue)), message.NewReceiver(queue, queueURL))
+}
+
+// SetupWebhookDeliverer builds the use case whose Run delivers the pending webhook deliveries
+func SetupWebhookDeliverer(cfg *config.Config, db *Databases) webhook.UseCase {
+	return newWebhookUseCase(newGateways(cfg, db, nil))
+}
+
+func newOrderUseCase(g *gateways) order.UseCase {
+	useCase := order.NewUseCase(g.orders, g.items)
+	useCase.Broadcaster = orderBroadcaster
+	return useCase
 }
 
-func SetupQueue() *sqs.SQS {
-	return external.GetSqsClient()
+func newWebhookUseCase(g *gateways) webhook.UseCase {
+	return webhook.NewUseCase(g.webhooks, whs.NewSender())
 }
 
-func SetupRouter(db *dynamodb.DynamoDB, queue *sqs.SQS) *chi.Mux {
+// SetupRouter maps the routes to use cases built with the gateways of the configured storage backend.
+// The debug routes are only mapped when they are enabled.
+func SetupRouter(cfg *config.Config, db *Databases, queue *sqs.SQS) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(commonMiddleware)
 
-	mapRoutes(r, db, queue)
+	mapRoutes(r, newGateways(cfg, db, queue))
+	if cfg.Server.Debug {
+		_ = controllers.NewDebugController(cfg, r)
+	}
 
 	return r
 }
 
-func mapRoutes(r *chi.Mux, db *dynamodb.DynamoDB, queue *sqs.SQS) {
+func mapRoutes(r *chi.Mux, g *gateways) {
 	// Swagger
 	r.Get("/swagger/*", httpSwagger.Handler())
 
-	// Gateways
-	orderGateway := og.NewGateway(db)
-	queueGateway := message.NewGateway(queue)
 	// Use cases
-	orderUseCase := order.NewUseCase(orderGateway, queueGateway)
+	orderUseCase := newOrderUseCase(g)
+	itemUseCase := item.NewUseCase(g.items)
+	idempotencyUseCase := idempotency.NewUseCase(g.idempotency)
+	webhookUseCase := newWebhookUseCase(g)
 	// Handlers
-	_ = controllers.NewOrderController(orderUseCase, r)
+	_ = controllers.NewOrderController(orderUseCase, idempotencyUseCase, r)
+	_ = controllers.NewItemController(itemUseCase, r)
+	_ = controllers.NewKitchenController(orderUseCase, r)
+	_ = controllers.NewWebhookController(webhookUseCase, r)
 }
 
 func commonMiddleware(next http.Handler) http.Handler {
@@ -0,0 +1,42 @@
+// Command migrate-orders prepares the orders table for this version of the API: it creates the indexes the listings
+// query and rewrites the orders written by older versions, timestamps stored as time.Time.String() become sortable
+// RFC 3339 UTC strings and item_ids is filled in. It is safe to run more than once.
+package main
+
+import (
+	"log"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/api"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	db := api.SetupDB(cfg)
+	if db.DynamoDB == nil {
+		log.Fatalln("Não há pedidos a migrar: STORAGE_BACKEND é memory")
+	}
+
+	gateway := og.NewGateway(db.DynamoDB, cfg.DynamoDB.Tables.Orders, cfg.DynamoDB.Tables.OrdersOutbox)
+	created, err := gateway.EnsureIndexes()
+	for _, index := range created {
+		log.Printf("Índice %s criado\n", index)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	result, err := gateway.Migrate()
+	log.Printf("Pedidos lidos: %d, migrados: %d, com falha: %d\n", result.Scanned, result.Migrated, result.Failed)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if result.Failed > 0 {
+		log.Fatalln("Alguns pedidos não foram migrados, execute a migração novamente")
+	}
+}
@@ -0,0 +1,311 @@
+// Package config holds the settings of the API. They are read from an optional YAML or JSON file,
+// named by the CONFIG_FILE env var, and from env vars, which take precedence over the file.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Storage backends, selected with the STORAGE_BACKEND env var
+const (
+	DynamoDBStorage = "dynamodb"
+	PostgresStorage = "postgres"
+	MemoryStorage   = "memory"
+)
+
+// defaultLocalDynamoDBEndpoint is where the docker-compose DynamoDB Local listens
+const defaultLocalDynamoDBEndpoint = "http://localhost:9000"
+
+const redacted = "REDACTED"
+
+type Config struct {
+	Server ServerConfig `json:"server" yaml:"server"`
+	// StorageBackend is where the data is kept: dynamodb, postgres (orders and their outbox, the rest in DynamoDB) or memory
+	StorageBackend string         `json:"storage_backend" yaml:"storage_backend"`
+	AWS            AWSConfig      `json:"aws" yaml:"aws"`
+	DynamoDB       DynamoDBConfig `json:"dynamodb" yaml:"dynamodb"`
+	Postgres       PostgresConfig `json:"postgres" yaml:"postgres"`
+	Queues         QueuesConfig   `json:"queues" yaml:"queues"`
+}
+
+type ServerConfig struct {
+	Address string `json:"address" yaml:"address"`
+	// Debug maps the /debug routes
+	Debug bool `json:"debug" yaml:"debug"`
+}
+
+type AWSConfig struct {
+	Region string `json:"region" yaml:"region"`
+	// Local connects to local stand-ins of the AWS services, creating the DynamoDB tables
+	Local bool `json:"local" yaml:"local"`
+}
+
+type DynamoDBConfig struct {
+	// Endpoint replaces the AWS endpoint, local runs default to DynamoDB Local
+	Endpoint string       `json:"endpoint" yaml:"endpoint"`
+	Tables   TablesConfig `json:"tables" yaml:"tables"`
+}
+
+type TablesConfig struct {
+	Orders               string `json:"orders" yaml:"orders"`
+	OrdersOutbox         string `json:"orders_outbox" yaml:"orders_outbox"`
+	Items                string `json:"items" yaml:"items"`
+	IdempotencyKeys      string `json:"idempotency_keys" yaml:"idempotency_keys"`
+	WebhookSubscriptions string `json:"webhook_subscriptions" yaml:"webhook_subscriptions"`
+	WebhookDeliveries    string `json:"webhook_deliveries" yaml:"webhook_deliveries"`
+}
+
+type PostgresConfig struct {
+	// DSN is secret, its password is redacted by Redacted
+	DSN string `json:"dsn" yaml:"dsn"`
+}
+
+type QueuesConfig struct {
+	// SQSEndpoint replaces the AWS endpoint. Local runs without it record the messages in memory.
+	SQSEndpoint string `json:"sqs_endpoint" yaml:"sqs_endpoint"`
+	// OrdersURL is the queue the order events are published to
+	OrdersURL string `json:"orders_url" yaml:"orders_url"`
+	// PaymentResultsURL is the queue the payment results are consumed from, the consumer is disabled without it
+	PaymentResultsURL string `json:"payment_results_url" yaml:"payment_results_url"`
+}
+
+// ValidationError lists every invalid setting, by its path in the config file
+type ValidationError struct {
+	Fields []util.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var problems []string
+	for _, field := range e.Fields {
+		problems = append(problems, fmt.Sprintf("%s %s", field.Field, field.Message))
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// Default returns the settings used when neither the file nor the env vars set them
+func Default() *Config {
+	return &Config{
+		Server:         ServerConfig{Address: ":8000"},
+		StorageBackend: DynamoDBStorage,
+		AWS:            AWSConfig{Region: "us-east-1"},
+		DynamoDB: DynamoDBConfig{Tables: TablesConfig{
+			Orders:               "orders",
+			OrdersOutbox:         "orders_outbox",
+			Items:                "items",
+			IdempotencyKeys:      "idempotency_keys",
+			WebhookSubscriptions: "webhook_subscriptions",
+			WebhookDeliveries:    "webhook_deliveries",
+		}},
+	}
+}
+
+// Load reads the config file of CONFIG_FILE, when it is set, over the defaults, then the env vars over both,
+// and validates the result
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := cfg.readEnv()
+	if cfg.AWS.Local && cfg.DynamoDB.Endpoint == "" {
+		cfg.DynamoDB.Endpoint = defaultLocalDynamoDBEndpoint
+	}
+
+	fields = append(fields, cfg.validate()...)
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+	return cfg, nil
+}
+
+// readFile decodes a .yaml, .yml or .json file. Unknown settings are errors, so typos do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		err = decoder.Decode(c)
+	case ".json":
+		decoder := json.NewDecoder(file)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .json", path)
+	}
+
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// readEnv sets the settings of the env vars that are set. It returns the env vars that could not be parsed.
+func (c *Config) readEnv() []util.FieldError {
+	texts := map[string]*string{
+		"SERVER_ADDRESS":              &c.Server.Address,
+		"STORAGE_BACKEND":             &c.StorageBackend,
+		"AWS_REGION":                  &c.AWS.Region,
+		"DYNAMODB_ENDPOINT":           &c.DynamoDB.Endpoint,
+		"ORDERS_TABLE":                &c.DynamoDB.Tables.Orders,
+		"ORDERS_OUTBOX_TABLE":         &c.DynamoDB.Tables.OrdersOutbox,
+		"ITEMS_TABLE":                 &c.DynamoDB.Tables.Items,
+		"IDEMPOTENCY_KEYS_TABLE":      &c.DynamoDB.Tables.IdempotencyKeys,
+		"WEBHOOK_SUBSCRIPTIONS_TABLE": &c.DynamoDB.Tables.WebhookSubscriptions,
+		"WEBHOOK_DELIVERIES_TABLE":    &c.DynamoDB.Tables.WebhookDeliveries,
+		"POSTGRES_DSN":                &c.Postgres.DSN,
+		"SQS_ENDPOINT":                &c.Queues.SQSEndpoint,
+		"QUEUE_URL":                   &c.Queues.OrdersURL,
+		"PAYMENT_RESULTS_QUEUE_URL":   &c.Queues.PaymentResultsURL,
+	}
+	for name, setting := range texts {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			*setting = value
+		}
+	}
+
+	var fields []util.FieldError
+	bools := []struct {
+		name    string
+		setting *bool
+	}{
+		{"DEBUG_ENDPOINTS", &c.Server.Debug},
+		{"IS_LOCAL", &c.AWS.Local},
+	}
+	for _, env := range bools {
+		name, setting := env.name, env.setting
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			fields = append(fields, util.FieldError{Field: name, Message: "must be true or false"})
+			continue
+		}
+		*setting = parsed
+	}
+	return fields
+}
+
+func (c *Config) validate() []util.FieldError {
+	var fields []util.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, util.FieldError{Field: field, Message: message})
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Address); err != nil {
+		invalid("server.address", "must be a host:port address, e.g. :8000")
+	}
+
+	switch c.StorageBackend {
+	case DynamoDBStorage, PostgresStorage, MemoryStorage:
+	default:
+		invalid("storage_backend", fmt.Sprintf("must be %s, %s or %s", DynamoDBStorage, PostgresStorage, MemoryStorage))
+		return fields
+	}
+
+	// The memory backend connects to nothing
+	if c.StorageBackend == MemoryStorage {
+		return fields
+	}
+
+	if c.AWS.Region == "" {
+		invalid("aws.region", "is required")
+	}
+
+	if c.DynamoDB.Endpoint != "" && !isHTTPURL(c.DynamoDB.Endpoint) {
+		invalid("dynamodb.endpoint", "must be an absolute http or https URL")
+	}
+	tables := []struct{ field, name string }{
+		{"orders", c.DynamoDB.Tables.Orders},
+		{"orders_outbox", c.DynamoDB.Tables.OrdersOutbox},
+		{"items", c.DynamoDB.Tables.Items},
+		{"idempotency_keys", c.DynamoDB.Tables.IdempotencyKeys},
+		{"webhook_subscriptions", c.DynamoDB.Tables.WebhookSubscriptions},
+		{"webhook_deliveries", c.DynamoDB.Tables.WebhookDeliveries},
+	}
+	for _, table := range tables {
+		if table.name == "" {
+			invalid("dynamodb.tables."+table.field, "is required")
+		}
+	}
+
+	if c.StorageBackend == PostgresStorage && c.Postgres.DSN == "" {
+		invalid("postgres.dsn", "is required when storage_backend is postgres")
+	}
+
+	if c.Queues.SQSEndpoint != "" && !isHTTPURL(c.Queues.SQSEndpoint) {
+		invalid("queues.sqs_endpoint", "must be an absolute http or https URL")
+	}
+	if c.UsesQueues() && !isHTTPURL(c.Queues.OrdersURL) {
+		invalid("queues.orders_url", "must be the URL of the orders queue")
+	}
+	if c.Queues.PaymentResultsURL != "" && !isHTTPURL(c.Queues.PaymentResultsURL) {
+		invalid("queues.payment_results_url", "must be an absolute http or https URL")
+	}
+
+	return fields
+}
+
+// UsesQueues reports whether messages are sent to SQS. The memory backend records them in memory
+// and local runs send none unless an SQS endpoint is set.
+func (c *Config) UsesQueues() bool {
+	return c.StorageBackend != MemoryStorage && (!c.AWS.Local || c.Queues.SQSEndpoint != "")
+}
+
+func isHTTPURL(value string) bool {
+	target, err := url.Parse(value)
+	return err == nil && (target.Scheme == "http" || target.Scheme == "https") && target.Host != ""
+}
+
+// dsnPassword matches the password of a key=value Postgres DSN
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// Redacted returns a copy of the settings that can be shown, with the secrets replaced
+func (c *Config) Redacted() *Config {
+	cfg := *c
+	if cfg.Postgres.DSN == "" {
+		return &cfg
+	}
+
+	dsn, err := url.Parse(cfg.Postgres.DSN)
+	switch {
+	case err != nil:
+		cfg.Postgres.DSN = redacted
+	case dsn.Scheme != "":
+		if _, hasPassword := dsn.User.Password(); hasPassword {
+			dsn.User = url.UserPassword(dsn.User.Username(), redacted)
+		}
+		query := dsn.Query()
+		if query.Has("password") {
+			query.Set("password", redacted)
+			dsn.RawQuery = query.Encode()
+		}
+		cfg.Postgres.DSN = dsn.String()
+	default:
+		cfg.Postgres.DSN = dsnPassword.ReplaceAllString(cfg.Postgres.DSN, "${1}"+redacted)
+	}
+	return &cfg
+}
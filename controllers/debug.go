@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
+)
+
+type DebugController struct {
+	config *config.Config
+}
+
+func NewDebugController(cfg *config.Config, r *chi.Mux) *DebugController {
+	controller := DebugController{config: cfg}
+	r.Route("/debug", func(r chi.Router) {
+		r.Get("/config", controller.GetConfig)
+	})
+	return &controller
+}
+
+// @Summary	Gets the configuration the API is running with
+// @Description	Secrets are redacted. The route is only mapped when DEBUG_ENDPOINTS is true.
+//
+// @Tags		Debug
+//
+// @ID			get-config
+// @Produce	json
+// @Success	200	{object}	config.Config
+// @Router		/debug/config [get]
+func (c *DebugController) GetConfig(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(c.config.Redacted())
+}
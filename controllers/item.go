@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/adapters/item"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+type ItemController struct {
+	useCase interfaces.ItemUseCase
+}
+
+func NewItemController(useCase interfaces.ItemUseCase, r *chi.Mux) *ItemController {
+	controller := ItemController{useCase: useCase}
+	r.Route("/itens", func(r chi.Router) {
+		r.Get("/", controller.GetAll)
+		r.Post("/", controller.Create)
+		r.Get("/{id}", controller.GetByID)
+		r.Put("/{id}", controller.Update)
+		r.Delete("/{id}", controller.Delete)
+	})
+	return &controller
+}
+
+// @Summary	Gets all items of the catalog
+//
+// @Tags		Items
+//
+// @ID			get-all-items
+// @Produce	json
+// @Success	200	{array}	item.Item
+// @Failure	500	{object}	controllers.Problem
+// @Router		/itens [get]
+func (c *ItemController) GetAll(w http.ResponseWriter, r *http.Request) {
+	itemsFetched, err := c.useCase.List()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	items := []*item.Item{}
+	for _, itemFetched := range *itemsFetched {
+		items = append(items, item.FromUseCaseEntity(&itemFetched))
+	}
+	json.NewEncoder(w).Encode(items)
+}
+
+// @Summary	Gets an item by ID
+//
+// @Tags		Items
+//
+// @ID			get-item-by-id
+// @Produce	json
+// @Param		id	path		string	true	"Item ID"
+// @Success	200	{object}	item.Item
+// @Failure	404	{object}	controllers.Problem
+// @Router		/itens/{id} [get]
+func (c *ItemController) GetByID(w http.ResponseWriter, r *http.Request) {
+	itemID := chi.URLParam(r, "id")
+
+	itemFetched, err := c.useCase.GetByID(itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if itemFetched == nil {
+		writeError(w, r, util.NewNotFoundError("item_not_found", fmt.Sprintf("Item ID %s not found", itemID)))
+		return
+	}
+	json.NewEncoder(w).Encode(item.FromUseCaseEntity(itemFetched))
+}
+
+// @Summary	New item
+//
+// @Tags		Items
+//
+// @ID			create-item
+// @Produce	json
+// @Param		data	body		item.Item	true	"Item payload"
+// @Success	201		{object}	item.Item
+// @Failure	400	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/itens [post]
+func (c *ItemController) Create(w http.ResponseWriter, r *http.Request) {
+	var itemModel item.Item
+	err := decodeBody(w, r, &itemModel)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemCreated, err := c.useCase.Create(itemModel.ToUseCaseEntity())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item.FromUseCaseEntity(itemCreated))
+}
+
+// @Summary	Updates an item
+//
+// @Tags		Items
+//
+// @ID			update-item
+// @Produce	json
+// @Param		id		path		string	true	"Item ID"
+// @Param		data	body		item.Item	true	"Item payload"
+// @Success	200		{object}	item.Item
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/itens/{id} [put]
+func (c *ItemController) Update(w http.ResponseWriter, r *http.Request) {
+	itemID := chi.URLParam(r, "id")
+
+	var itemModel item.Item
+	err := decodeBody(w, r, &itemModel)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	itemUpdated, err := c.useCase.Update(itemID, itemModel.ToUseCaseEntity())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if itemUpdated == nil {
+		writeError(w, r, util.NewNotFoundError("item_not_found", fmt.Sprintf("Item ID %s not found", itemID)))
+		return
+	}
+	json.NewEncoder(w).Encode(item.FromUseCaseEntity(itemUpdated))
+}
+
+// @Summary	Deletes an item by ID
+//
+// @Tags		Items
+//
+// @ID			delete-item-by-id
+// @Produce	json
+// @Param		id	path	string	true	"Item ID"
+// @Success	204
+// @Failure	404	{object}	controllers.Problem
+// @Router		/itens/{id} [delete]
+func (c *ItemController) Delete(w http.ResponseWriter, r *http.Request) {
+	itemID := chi.URLParam(r, "id")
+
+	err := c.useCase.Delete(itemID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
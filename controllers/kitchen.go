@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/adapters/kitchen"
+	order "github.com/postech-soat2-grupo16/pedidos-api/adapters/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+type KitchenController struct {
+	useCase interfaces.OrderUseCase
+}
+
+func NewKitchenController(useCase interfaces.OrderUseCase, r *chi.Mux) *KitchenController {
+	controller := KitchenController{useCase: useCase}
+	r.Route("/cozinha", func(r chi.Router) {
+		r.Get("/fila", controller.GetQueue)
+		r.Post("/fila/{id}/claim", controller.Claim)
+	})
+	return &controller
+}
+
+// @Summary	Gets the kitchen queue
+// @Description	Received and in preparation orders, the orders in preparation first and then by arrival in the kitchen, with the quantity of every item to prepare.
+//
+// @Tags		Kitchen
+//
+// @ID			get-kitchen-queue
+// @Produce	json
+// @Success	200	{object}	kitchen.Queue
+// @Failure	500	{object}	controllers.Problem
+// @Router		/cozinha/fila [get]
+func (c *KitchenController) GetQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := c.useCase.KitchenQueue()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(kitchen.QueueFromUseCaseEntity(queue))
+}
+
+// @Summary	Claims a queued order for a kitchen station
+// @Description	A received order starts being prepared when a station claims it. An order claimed by another station cannot be claimed.
+//
+// @Tags		Kitchen
+//
+// @ID			claim-order
+// @Produce	json
+// @Param		id		path		string	true	"Order ID"
+// @Param		X-Actor	header		string	false	"Who is claiming the order"
+// @Param		If-Match	header		string	false	"ETag of the order version being claimed"
+// @Param		data	body		kitchen.Claim	true	"Station claiming the order"
+// @Success	200		{object}	order.Order
+// @Header		200		{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/cozinha/fila/{id}/claim [post]
+func (c *KitchenController) Claim(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
+		return
+	}
+
+	var claim kitchen.Claim
+	if err := decodeBody(w, r, &claim); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, ok, err := versionFromIfMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if ok {
+		claim.Version = version
+	}
+
+	orderClaimed, err := c.useCase.Claim(orderID, claim.Station, r.Header.Get("X-Actor"), claim.Version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if orderClaimed == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
+		return
+	}
+
+	setETag(w, orderClaimed)
+	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderClaimed))
+}
@@ -2,27 +2,46 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	order "github.com/postech-soat2-grupo16/pedidos-api/adapters/order"
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
 	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type OrderController struct {
-	useCase interfaces.OrderUseCase
+	useCase            interfaces.OrderUseCase
+	idempotencyUseCase interfaces.IdempotencyUseCase
+	// HeartbeatInterval is how often the order streams send a heartbeat event
+	HeartbeatInterval time.Duration
 }
 
-func NewOrderController(useCase interfaces.OrderUseCase, r *chi.Mux) *OrderController {
-	controller := OrderController{useCase: useCase}
+func NewOrderController(useCase interfaces.OrderUseCase, idempotencyUseCase interfaces.IdempotencyUseCase,
+	r *chi.Mux) *OrderController {
+	controller := OrderController{
+		useCase:            useCase,
+		idempotencyUseCase: idempotencyUseCase,
+		HeartbeatInterval:  defaultHeartbeatInterval,
+	}
 	r.Route("/pedidos", func(r chi.Router) {
 		r.Get("/", controller.GetAll)
 		r.Post("/", controller.Create)
+		r.Get("/stream", controller.Stream)
 		r.Get("/{id}", controller.GetByID)
+		r.Get("/{id}/history", controller.GetStatusHistory)
+		r.Get("/{id}/stream", controller.StreamOrder)
 		r.Put("/{id}", controller.Update)
 		r.Delete("/{id}", controller.Delete)
 		r.Patch("/{id}", controller.PatchOrderStatus)
+		r.Post("/{id}/cancel", controller.Cancel)
+		r.Post("/{id}/restore", controller.Restore)
 		r.Get("/healthcheck", controller.Ping)
 	})
 	return &controller
@@ -46,30 +65,128 @@ func (c *OrderController) Ping(w http.ResponseWriter, r *http.Request) {
 // @Produce	json
 //
 // @Param       client_id  query       string  false   "Optional Filter by client_id"
-// @Param       status  query       string  false   "Optional Filter by order status"
+// @Param       status  query       string  false   "Optional Filter by order status, comma separated (PRONTO,ENTREGUE)"
+// @Param       created_from  query       string  false   "Optional Filter by creation time, RFC 3339, inclusive"
+// @Param       created_to  query       string  false   "Optional Filter by creation time, RFC 3339, inclusive"
+// @Param       item_id  query       string  false   "Optional Filter by an item contained in the order"
+// @Param       sort  query       string  false   "created_at (oldest first, default) or -created_at (newest first)"
+// @Param       limit  query       int  false   "Max orders per page (default 50, max 100)"
+// @Param       cursor  query       string  false   "next_cursor returned by the previous page, with the same filters"
+// @Param       include_deleted  query       bool  false   "Admin only: also list soft deleted orders"
+// @Param       X-Roles  header       string  false   "Roles of the caller, comma separated"
 //
-// @Success	200	{object}	order.Order
-// @Failure	500
+// @Success	200	{object}	order.OrderPage
+// @Failure	400	{object}	controllers.Problem
+// @Failure	403	{object}	controllers.Problem
+// @Failure	500	{object}	controllers.Problem
 // @Router		/pedidos [get]
 func (c *OrderController) GetAll(w http.ResponseWriter, r *http.Request) {
-	clientID := r.URL.Query().Get("client_id")
-	status := r.URL.Query().Get("status")
+	filter, err := orderFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if filter.IncludeDeleted && !hasRole(r, adminRole) {
+		writeError(w, r, util.NewForbiddenError("admin_required", "Only admins can list soft deleted orders"))
+		return
+	}
 
-	ordersFetched, err := c.useCase.List(clientID, status)
+	page, err := c.useCase.List(filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order.PageFromUseCaseEntity(page))
+}
+
+// adminRole is the role of the callers allowed to see soft deleted orders
+const adminRole = "admin"
+
+// hasRole reports whether the X-Roles header of the request, a comma separated list, has the role
+func hasRole(r *http.Request, role string) bool {
+	for _, granted := range strings.Split(r.Header.Get("X-Roles"), ",") {
+		if strings.TrimSpace(granted) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// orderFilterFromQuery reads the listing filters from the query string
+func orderFilterFromQuery(query url.Values) (entities.OrderFilter, error) {
+	filter := entities.OrderFilter{
+		ClientID: query.Get("client_id"),
+		ItemID:   query.Get("item_id"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	filter.Statuses = statusesFromQuery(query)
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			return filter, invalidParameter("limit", "must be a number")
+		}
+		filter.Limit = limit
+	}
+
+	if includeDeletedParam := query.Get("include_deleted"); includeDeletedParam != "" {
+		includeDeleted, err := strconv.ParseBool(includeDeletedParam)
+		if err != nil {
+			return filter, invalidParameter("include_deleted", "must be true or false")
+		}
+		filter.IncludeDeleted = includeDeleted
 	}
 
-	var orders []*order.Order
+	var err error
+	if filter.CreatedFrom, err = timeParameter(query, "created_from"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = timeParameter(query, "created_to"); err != nil {
+		return filter, err
+	}
 
-	if ordersFetched != nil {
-		for _, orderFetched := range *ordersFetched {
-			orders = append(orders, order.FromUseCaseEntity(&orderFetched))
+	switch query.Get("sort") {
+	case "", "created_at":
+	case "-created_at":
+		filter.Descending = true
+	default:
+		return filter, invalidParameter("sort", "must be created_at or -created_at")
+	}
+
+	return filter, nil
+}
+
+// statusesFromQuery reads the status filter, given as comma separated values, repeated parameters or both
+func statusesFromQuery(query url.Values) []entities.Status {
+	var statuses []entities.Status
+	for _, values := range query["status"] {
+		for _, status := range strings.Split(values, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				statuses = append(statuses, entities.Status(status))
+			}
 		}
-		json.NewEncoder(w).Encode(orders)
 	}
+	return statuses
+}
 
-	json.NewEncoder(w).Encode([]*order.Order{})
+// timeParameter reads an optional RFC 3339 query parameter, the zero time when it is missing
+func timeParameter(query url.Values, param string) (time.Time, error) {
+	value := query.Get(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, invalidParameter(param, "must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+func invalidParameter(param, message string) error {
+	return util.NewBadRequestError("invalid_parameter", fmt.Sprintf("%s %s", param, message),
+		util.FieldError{Field: param, Message: message})
 }
 
 // @Summary	Gets an order by ID
@@ -80,52 +197,127 @@ func (c *OrderController) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Produce	json
 // @Param		id	path		string	true	"Order ID"
 // @Success	200	{object}	order.Order
-// @Failure	404
+// @Header		200	{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
 // @Router		/pedidos/{id} [get]
 func (c *OrderController) GetByID(w http.ResponseWriter, r *http.Request) {
 	orderID := chi.URLParam(r, "id")
 	if orderID == "" {
-		http.Error(w, util.NewErrorDomain("order_id URL Param is missing").Error(), http.StatusBadRequest)
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
 		return
 	}
 
 	orderFetched, err := c.useCase.GetByID(orderID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
 	}
 	if orderFetched == nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
 		return
 	}
+	setETag(w, orderFetched)
 	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderFetched))
 }
 
+// @Summary	Gets the status history of an order
+//
+// @Tags		Orders
+//
+// @ID			get-order-status-history
+// @Produce	json
+// @Param		id	path		string	true	"Order ID"
+// @Success	200	{array}		order.StatusChange
+// @Failure	404	{object}	controllers.Problem
+// @Router		/pedidos/{id}/history [get]
+func (c *OrderController) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
+		return
+	}
+
+	orderFetched, err := c.useCase.GetByID(orderID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if orderFetched == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
+		return
+	}
+	json.NewEncoder(w).Encode(order.StatusHistoryFromEntity(orderFetched))
+}
+
 // @Summary	New order
+// @Description	Orders start as CRIADO: the status may be left out, any other status is rejected.
 //
 // @Tags		Orders
 //
 // @ID			create-order
 // @Produce	json
 // @Param		data	body		order.Order	true	"Order payload"
-// @Success	200		{object}	order.Order
-// @Failure	400
+// @Param		Idempotency-Key	header	string	false	"Retries with the same key get the response of the first request instead of a new order"
+// @Success	201		{object}	order.Order
+// @Header		201		{string}	Idempotent-Replayed	"true when the response is the one of a previous request"
+// @Failure	400	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
 // @Router		/pedidos [post]
 func (c *OrderController) Create(w http.ResponseWriter, r *http.Request) {
 	var orderModel order.Order
-	err := json.NewDecoder(r.Body).Decode(&orderModel)
+	err := decodeBody(w, r, &orderModel)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("Error parsing request body"))
+		writeError(w, r, err)
 		return
 	}
-	orderCreated, err := c.useCase.Create(orderModel.ToUseCaseEntity())
+
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		c.create(w, r, &orderModel)
+		return
+	}
+
+	request, err := json.Marshal(orderModel)
 	if err != nil {
-		if util.IsDomainError(err) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(err)
-			return
+		writeError(w, r, err)
+		return
+	}
+
+	record, err := c.idempotencyUseCase.Start(orderModel.ClientID, key, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if record.IsCompleted() {
+		w.Header().Set("Idempotent-Replayed", "true")
+		if record.ContentType != "" {
+			w.Header().Set("Content-Type", record.ContentType)
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		w.WriteHeader(record.StatusCode)
+		w.Write([]byte(record.Response))
+		return
+	}
+
+	// Unexpected failures are not kept, so the client can retry them with the same key
+	recorder := newResponseRecorder(w)
+	c.create(recorder, r, &orderModel)
+	if recorder.statusCode >= http.StatusInternalServerError {
+		err = c.idempotencyUseCase.Release(record)
+	} else {
+		err = c.idempotencyUseCase.Complete(record, recorder.statusCode, recorder.Header().Get("Content-Type"),
+			recorder.body.Bytes())
+	}
+	if err != nil {
+		log.Printf("Erro ao salvar a resposta da Idempotency-Key %s: %s\n", key, err)
+	}
+}
+
+func (c *OrderController) create(w http.ResponseWriter, r *http.Request, orderModel *order.Order) {
+	orderCreated, err := c.useCase.Create(orderModel.ToUseCaseEntity())
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -133,125 +325,252 @@ func (c *OrderController) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary	Updates an order
+// @Description	Items and notes can only change while the order is CRIADO or NEGADO. Changing the status to CANCELADO
+// @Description	cancels the order, which requires a reason and no other change.
 //
 // @Tags		Orders
 //
 // @ID			update-order
 // @Produce	json
 // @Param		id		path		string	true	"Order ID"
+// @Param		X-Actor	header		string	false	"Who is updating the order"
+// @Param		If-Match	header		string	false	"ETag of the order version being updated"
 // @Param		data	body		order.Order	true	"Order payload"
 // @Success	200		{object}	order.Order
-// @Failure	404
-// @Failure	400
+// @Header		200		{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
 // @Router		/pedidos/{id} [put]
 func (c *OrderController) Update(w http.ResponseWriter, r *http.Request) {
 	orderID := chi.URLParam(r, "id")
 	if orderID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("id URL Param is missing"))
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
 		return
 	}
 
 	var o order.Order
-	err := json.NewDecoder(r.Body).Decode(&o)
+	err := decodeBody(w, r, &o)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("Error parsing request body"))
+		writeError(w, r, err)
 		return
 	}
 
-	order, err := c.useCase.Update(orderID, o.ToUseCaseEntity())
+	version, ok, err := versionFromIfMatch(r)
 	if err != nil {
-		if util.IsDomainError(err) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(err)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	if ok {
+		o.Version = version
+	}
+
+	orderUpdated, err := c.useCase.Update(orderID, o.ToUseCaseEntity(), r.Header.Get("X-Actor"), o.Reason)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-	if order == nil {
-		w.WriteHeader(http.StatusNotFound)
+	if orderUpdated == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
 		return
 	}
+	setETag(w, orderUpdated)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(order)
+	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderUpdated))
 }
 
 // @Summary	Patches order's status
+// @Description	Only transitions allowed by the order lifecycle are accepted. The response lists the next allowed statuses.
 //
 // @Tags		Orders
 //
 // @ID			update-status-order
 // @Produce	json
 // @Param		id		path		string	true	"Order ID"
-// @Param		data	body		order.Order	true	"Order with updated status"
+// @Param		X-Actor	header		string	false	"Who is changing the status"
+// @Param		If-Match	header		string	false	"ETag of the order version being updated"
+// @Param		data	body		order.StatusUpdate	true	"New status and the reason of the change"
 // @Success	200		{object}	order.Order
-// @Failure	404
-// @Failure	400
+// @Header		200		{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
 // @Router		/pedidos/{id} [patch]
 func (c *OrderController) PatchOrderStatus(w http.ResponseWriter, r *http.Request) {
 	orderID := chi.URLParam(r, "id")
 	if orderID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("id URL Param is missing"))
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
 		return
 	}
 
-	var o order.Order
-	err := json.NewDecoder(r.Body).Decode(&o)
+	var statusUpdate order.StatusUpdate
+	err := decodeBody(w, r, &statusUpdate)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("Error parsing request body"))
+		writeError(w, r, err)
 		return
 	}
 
-	order, err := c.useCase.UpdateOrderStatus(orderID, entities.Status(o.Status))
+	version, ok, err := versionFromIfMatch(r)
 	if err != nil {
-		if util.IsDomainError(err) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(err)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	if ok {
+		statusUpdate.Version = version
+	}
+
+	orderUpdated, err := c.useCase.UpdateOrderStatus(orderID, entities.Status(statusUpdate.Status),
+		r.Header.Get("X-Actor"), statusUpdate.Reason, statusUpdate.Version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if orderUpdated == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
+		return
+	}
+
+	setETag(w, orderUpdated)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderUpdated))
+}
+
+// @Summary	Cancels an order
+// @Description	Only orders that are not being prepared yet can be cancelled. A cancellation event is published so the payment can be refunded.
+//
+// @Tags		Orders
+//
+// @ID			cancel-order
+// @Produce	json
+// @Param		id		path		string	true	"Order ID"
+// @Param		X-Actor	header		string	false	"Who is cancelling the order"
+// @Param		If-Match	header		string	false	"ETag of the order version being cancelled"
+// @Param		data	body		order.Cancellation	true	"Reason of the cancellation"
+// @Success	200		{object}	order.Order
+// @Header		200		{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/pedidos/{id}/cancel [post]
+func (c *OrderController) Cancel(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
+		return
+	}
+
+	var cancellation order.Cancellation
+	err := decodeBody(w, r, &cancellation)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, ok, err := versionFromIfMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if ok {
+		cancellation.Version = version
+	}
+
+	orderCancelled, err := c.useCase.Cancel(orderID, r.Header.Get("X-Actor"), cancellation.Reason, cancellation.Version)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if order == nil {
-		w.WriteHeader(http.StatusNotFound)
+	if orderCancelled == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
 		return
 	}
 
+	setETag(w, orderCancelled)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(order)
+	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderCancelled))
 }
 
 // @Summary	Deletes an order by ID
+// @Description	The order is soft deleted: it is hidden from the API but kept, and can be restored.
 //
 // @Tags		Orders
 //
 // @ID			delete-order-by-id
 // @Produce	json
 // @Param		id	path	string	true	"Order ID"
+// @Param		X-Actor	header	string	false	"Who is deleting the order"
 // @Success	204
-// @Failure	500
+// @Failure	500	{object}	controllers.Problem
 // @Router		/pedidos/{id} [delete]
 func (c *OrderController) Delete(w http.ResponseWriter, r *http.Request) {
 	orderID := chi.URLParam(r, "id")
 	if orderID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(util.NewErrorDomain("id URL Param is missing"))
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
 		return
 	}
 
-	err := c.useCase.Delete(orderID)
+	err := c.useCase.Delete(orderID, r.Header.Get("X-Actor"))
 	if err != nil {
-		if util.IsDomainError(err) {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// @Summary	Restores a deleted order
+//
+// @Tags		Orders
+//
+// @ID			restore-order
+// @Produce	json
+// @Param		id		path		string	true	"Order ID"
+// @Param		X-Actor	header		string	false	"Who is restoring the order"
+// @Success	200		{object}	order.Order
+// @Header		200		{string}	ETag	"Order version"
+// @Failure	404	{object}	controllers.Problem
+// @Failure	409	{object}	controllers.Problem
+// @Router		/pedidos/{id}/restore [post]
+func (c *OrderController) Restore(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
+		return
+	}
+
+	orderRestored, err := c.useCase.Restore(orderID, r.Header.Get("X-Actor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if orderRestored == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
+		return
+	}
+
+	setETag(w, orderRestored)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order.FromUseCaseEntity(orderRestored))
+}
+
+func setETag(w http.ResponseWriter, o *entities.Order) {
+	w.Header().Set("ETag", fmt.Sprintf("%q", strconv.Itoa(o.Version)))
+}
+
+// versionFromIfMatch reads the order version from the If-Match header. ok is false when there is no precondition.
+func versionFromIfMatch(r *http.Request) (version int, ok bool, err error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, false, nil
+	}
+
+	version, err = strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, false, util.NewBadRequestError("invalid_if_match", fmt.Sprintf("If-Match %s is not a valid order ETag", ifMatch))
+	}
+
+	return version, true, nil
+}
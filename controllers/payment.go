@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+)
+
+const (
+	paymentBatchSize    = 10
+	paymentErrorBackoff = 5 * time.Second
+	paymentActor        = "pagamentos-api"
+)
+
+// PaymentConsumer advances orders to APROVADO or NEGADO from the payment results queue.
+// Messages are deleted only when handled; the ones that keep failing are moved to the DLQ by the queue redrive policy.
+type PaymentConsumer struct {
+	useCase  interfaces.OrderUseCase
+	receiver interfaces.MessageReceiverI
+}
+
+func NewPaymentConsumer(useCase interfaces.OrderUseCase, receiver interfaces.MessageReceiverI) *PaymentConsumer {
+	return &PaymentConsumer{
+		useCase:  useCase,
+		receiver: receiver,
+	}
+}
+
+// Run consumes the queue until the context is done
+func (c *PaymentConsumer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := c.ConsumeBatch(); err != nil {
+			log.Printf("Erro ao consumir resultados de pagamento: %s\n", err)
+			time.Sleep(paymentErrorBackoff)
+		}
+	}
+}
+
+// ConsumeBatch receives and handles one batch of messages
+func (c *PaymentConsumer) ConsumeBatch() error {
+	messages, err := c.receiver.ReceiveMessages(paymentBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if err := c.handle(message); err != nil {
+			log.Printf("Mensagem %s não processada: %s\n", message.MessageID, err)
+			continue
+		}
+
+		if err := c.receiver.DeleteMessage(message.ReceiptHandle); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *PaymentConsumer) handle(message entities.QueueMessage) error {
+	var result entities.PaymentResult
+	if err := json.Unmarshal([]byte(message.Body), &result); err != nil {
+		return fmt.Errorf("invalid payment result: %w", err)
+	}
+
+	if !result.IsValid() {
+		return fmt.Errorf("invalid payment result for order %q with status %q", result.OrderID, result.Status)
+	}
+
+	reason := fmt.Sprintf("payment %s", result.PaymentID)
+	orderUpdated, err := c.useCase.UpdateOrderStatus(result.OrderID, result.Status, paymentActor, reason, 0)
+	if err != nil {
+		return err
+	}
+
+	if orderUpdated == nil {
+		return fmt.Errorf("order %s not found", result.OrderID)
+	}
+
+	log.Printf("Pagamento do pedido %s: %s\n", result.OrderID, result.Status)
+	return nil
+}
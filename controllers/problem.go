@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 body of every error response
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	Errors   []util.FieldError `json:"errors,omitempty"`
+}
+
+var problemStatuses = map[util.ErrorCategory]int{
+	util.BadRequestCategory:        http.StatusBadRequest,
+	util.ValidationCategory:        http.StatusUnprocessableEntity,
+	util.NotFoundCategory:          http.StatusNotFound,
+	util.ConflictCategory:          http.StatusConflict,
+	util.InvalidTransitionCategory: http.StatusUnprocessableEntity,
+	util.ForbiddenCategory:         http.StatusForbidden,
+}
+
+var problemTitles = map[util.ErrorCategory]string{
+	util.BadRequestCategory:        "Malformed request",
+	util.ValidationCategory:        "Invalid request",
+	util.NotFoundCategory:          "Resource not found",
+	util.ConflictCategory:          "Conflicting change",
+	util.InvalidTransitionCategory: "Transition not allowed",
+	util.ForbiddenCategory:         "Forbidden",
+}
+
+// writeError renders err as a problem. The status code comes from the domain error category,
+// errors that are not domain errors are logged and reported as internal server errors.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	domainErr, ok := util.AsErrorDomain(err)
+	if !ok {
+		log.Printf("Erro inesperado em %s %s: %s\n", r.Method, r.URL.Path, err)
+		writeProblem(w, r, Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+		})
+		return
+	}
+
+	status, ok := problemStatuses[domainErr.Category]
+	if !ok {
+		status = http.StatusUnprocessableEntity
+	}
+
+	writeProblem(w, r, Problem{
+		Type:   problemType(domainErr.Code),
+		Title:  problemTitles[domainErr.Category],
+		Status: status,
+		Detail: domainErr.Message,
+		Code:   domainErr.Code,
+		Errors: domainErr.Fields,
+	})
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Instance = r.URL.Path
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+func problemType(code string) string {
+	if code == "" {
+		return "about:blank"
+	}
+	return "urn:pedidos-api:problem:" + code
+}
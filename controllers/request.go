@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+// maxBodyBytes is the largest request body accepted
+const maxBodyBytes = 64 << 10
+
+// decodeBody decodes the JSON request body into v. Fields v does not have and bodies larger than maxBodyBytes
+// are rejected, so typos and oversized payloads are reported instead of silently ignored.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return util.NewBadRequestError("body_too_large",
+			fmt.Sprintf("Request body must have at most %d bytes", maxBodyBytes))
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return util.NewBadRequestError("invalid_body", "Error parsing request body",
+			util.FieldError{Field: typeErr.Field, Message: fmt.Sprintf("must be a %s", typeErr.Type)})
+	}
+
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+		return util.NewBadRequestError("unknown_field", fmt.Sprintf("Field %s is not accepted", field),
+			util.FieldError{Field: field, Message: "is not a known field"})
+	}
+
+	return util.NewBadRequestError("invalid_body", "Error parsing request body")
+}
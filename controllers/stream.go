@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	order "github.com/postech-soat2-grupo16/pedidos-api/adapters/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	heartbeatEvent           = "heartbeat"
+)
+
+// Stream @Summary	Streams the order events
+// @Description	Server-Sent Events stream of the order events: every event has the event type as name and the order event as data.
+// @Description	A heartbeat event is sent while there are no order events. Reconnections resume after the Last-Event-ID header, from the recent events kept by the server.
+//
+// @Tags		Orders
+//
+// @ID			stream-orders
+// @Produce	text/event-stream
+// @Param       client_id  query       string  false   "Optional Filter by client_id"
+// @Param       status  query       string  false   "Optional Filter by order status after the event, comma separated"
+// @Param		Last-Event-ID	header		string	false	"ID of the last event received, to resume the stream"
+// @Success	200	{object}	order.Event
+// @Failure	400	{object}	controllers.Problem
+// @Failure	500	{object}	controllers.Problem
+// @Router		/pedidos/stream [get]
+func (c *OrderController) Stream(w http.ResponseWriter, r *http.Request) {
+	c.stream(w, r, entities.StreamFilter{
+		ClientID: r.URL.Query().Get("client_id"),
+		Statuses: statusesFromQuery(r.URL.Query()),
+	})
+}
+
+// @Summary	Streams the events of an order
+// @Description	Server-Sent Events stream of the events of one order, see /pedidos/stream.
+//
+// @Tags		Orders
+//
+// @ID			stream-order
+// @Produce	text/event-stream
+// @Param		id	path		string	true	"Order ID"
+// @Param		Last-Event-ID	header		string	false	"ID of the last event received, to resume the stream"
+// @Success	200	{object}	order.Event
+// @Failure	400	{object}	controllers.Problem
+// @Failure	404	{object}	controllers.Problem
+// @Failure	500	{object}	controllers.Problem
+// @Router		/pedidos/{id}/stream [get]
+func (c *OrderController) StreamOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		writeError(w, r, util.NewBadRequestError("missing_id", "id URL Param is missing"))
+		return
+	}
+
+	orderFetched, err := c.useCase.GetByID(orderID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if orderFetched == nil {
+		writeError(w, r, util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID)))
+		return
+	}
+
+	c.stream(w, r, entities.StreamFilter{OrderID: orderID})
+}
+
+// stream sends the events of the subscription until the client disconnects or falls too far behind
+func (c *OrderController) stream(w http.ResponseWriter, r *http.Request, filter entities.StreamFilter) {
+	lastEventID, err := lastEventIDFromHeader(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errors.New("response writer does not support streaming"))
+		return
+	}
+
+	subscription, err := c.useCase.Subscribe(filter, lastEventID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, streamed := range subscription.Replay {
+		if err := writeEvent(w, streamed); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(c.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case streamed, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, streamed); err != nil {
+				return
+			}
+		case now := <-heartbeat.C:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: {\"time\":%q}\n\n", heartbeatEvent,
+				now.UTC().Format(time.RFC3339)); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, streamed entities.StreamedEvent) error {
+	data, err := json.Marshal(order.EventFromUseCaseEntity(&streamed.Event))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", streamed.ID, streamed.Event.Type, data)
+	return err
+}
+
+// lastEventIDFromHeader reads the ID of the last event received by a reconnecting stream, zero when there is none
+func lastEventIDFromHeader(r *http.Request) (uint64, error) {
+	lastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastEventID == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return 0, util.NewBadRequestError("invalid_last_event_id", fmt.Sprintf("Last-Event-ID %s is not a valid event ID", lastEventID))
+	}
+	return id, nil
+}
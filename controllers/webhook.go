@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/adapters/webhook"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+type WebhookController struct {
+	useCase interfaces.WebhookUseCase
+}
+
+func NewWebhookController(useCase interfaces.WebhookUseCase, r *chi.Mux) *WebhookController {
+	controller := WebhookController{useCase: useCase}
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", controller.GetAll)
+		r.Post("/", controller.Create)
+		r.Get("/{id}", controller.GetByID)
+		r.Put("/{id}", controller.Update)
+		r.Delete("/{id}", controller.Delete)
+		r.Get("/{id}/deliveries", controller.GetDeliveries)
+		r.Post("/{id}/deliveries/{deliveryId}/redeliver", controller.Redeliver)
+	})
+	return &controller
+}
+
+// @Summary	Gets all webhook subscriptions
+//
+// @Tags		Webhooks
+//
+// @ID			get-all-webhooks
+// @Produce	json
+// @Success	200	{array}	webhook.Subscription
+// @Failure	500	{object}	controllers.Problem
+// @Router		/webhooks [get]
+func (c *WebhookController) GetAll(w http.ResponseWriter, r *http.Request) {
+	subscriptionsFetched, err := c.useCase.ListSubscriptions()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	subscriptions := []*webhook.Subscription{}
+	for i := range subscriptionsFetched {
+		subscriptions = append(subscriptions, webhook.FromUseCaseEntity(&subscriptionsFetched[i]))
+	}
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+// @Summary	Gets a webhook subscription by ID
+//
+// @Tags		Webhooks
+//
+// @ID			get-webhook-by-id
+// @Produce	json
+// @Param		id	path		string	true	"Subscription ID"
+// @Success	200	{object}	webhook.Subscription
+// @Failure	404	{object}	controllers.Problem
+// @Router		/webhooks/{id} [get]
+func (c *WebhookController) GetByID(w http.ResponseWriter, r *http.Request) {
+	subscriptionID := chi.URLParam(r, "id")
+
+	subscriptionFetched, err := c.useCase.GetSubscription(subscriptionID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if subscriptionFetched == nil {
+		writeError(w, r, subscriptionNotFound(subscriptionID))
+		return
+	}
+	json.NewEncoder(w).Encode(webhook.FromUseCaseEntity(subscriptionFetched))
+}
+
+// @Summary	Registers a webhook
+// @Description	Order status changes are posted to the URL. Every delivery is signed: X-Webhook-Signature is "sha256=" followed by
+// @Description	the hex HMAC-SHA256, keyed with the secret, of the X-Webhook-Timestamp header, a dot and the request body.
+// @Description	The URL must reach a public address, redirects are not followed.
+//
+// @Tags		Webhooks
+//
+// @ID			create-webhook
+// @Produce	json
+// @Param		data	body		webhook.Subscription	true	"Subscription payload"
+// @Success	201		{object}	webhook.Subscription
+// @Failure	400	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/webhooks [post]
+func (c *WebhookController) Create(w http.ResponseWriter, r *http.Request) {
+	var subscriptionModel webhook.Subscription
+	if err := decodeBody(w, r, &subscriptionModel); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	subscriptionCreated, err := c.useCase.CreateSubscription(subscriptionModel.ToUseCaseEntity())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook.FromUseCaseEntity(subscriptionCreated))
+}
+
+// @Summary	Updates a webhook subscription
+// @Description	The secret is kept when the payload has none.
+//
+// @Tags		Webhooks
+//
+// @ID			update-webhook
+// @Produce	json
+// @Param		id		path		string	true	"Subscription ID"
+// @Param		data	body		webhook.Subscription	true	"Subscription payload"
+// @Success	200		{object}	webhook.Subscription
+// @Failure	404	{object}	controllers.Problem
+// @Failure	400	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/webhooks/{id} [put]
+func (c *WebhookController) Update(w http.ResponseWriter, r *http.Request) {
+	subscriptionID := chi.URLParam(r, "id")
+
+	var subscriptionModel webhook.Subscription
+	if err := decodeBody(w, r, &subscriptionModel); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	subscriptionUpdated, err := c.useCase.UpdateSubscription(subscriptionID, subscriptionModel.ToUseCaseEntity())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if subscriptionUpdated == nil {
+		writeError(w, r, subscriptionNotFound(subscriptionID))
+		return
+	}
+	json.NewEncoder(w).Encode(webhook.FromUseCaseEntity(subscriptionUpdated))
+}
+
+// @Summary	Deletes a webhook subscription
+//
+// @Tags		Webhooks
+//
+// @ID			delete-webhook
+// @Param		id	path	string	true	"Subscription ID"
+// @Success	204
+// @Failure	404	{object}	controllers.Problem
+// @Router		/webhooks/{id} [delete]
+func (c *WebhookController) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := c.useCase.DeleteSubscription(chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary	Gets the delivery log of a webhook subscription
+//
+// @Tags		Webhooks
+//
+// @ID			get-webhook-deliveries
+// @Produce	json
+// @Param		id		path		string	true	"Subscription ID"
+// @Param       limit  query       int  false   "Max deliveries, newest first (default and max 100)"
+// @Success	200	{array}	webhook.Delivery
+// @Failure	400	{object}	controllers.Problem
+// @Failure	404	{object}	controllers.Problem
+// @Router		/webhooks/{id}/deliveries [get]
+func (c *WebhookController) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			writeError(w, r, invalidParameter("limit", "must be a number"))
+			return
+		}
+	}
+
+	deliveriesFetched, err := c.useCase.ListDeliveries(chi.URLParam(r, "id"), limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	deliveries := []*webhook.Delivery{}
+	for i := range deliveriesFetched {
+		deliveries = append(deliveries, webhook.DeliveryFromUseCaseEntity(&deliveriesFetched[i]))
+	}
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// @Summary	Sends a webhook delivery again
+// @Description	The delivery is sent right away and retried as a new delivery when it fails. The subscription must be active.
+//
+// @Tags		Webhooks
+//
+// @ID			redeliver-webhook
+// @Produce	json
+// @Param		id			path		string	true	"Subscription ID"
+// @Param		deliveryId	path		string	true	"Delivery ID"
+// @Success	200	{object}	webhook.Delivery
+// @Failure	404	{object}	controllers.Problem
+// @Failure	422	{object}	controllers.Problem
+// @Router		/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (c *WebhookController) Redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, err := c.useCase.Redeliver(chi.URLParam(r, "id"), chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(webhook.DeliveryFromUseCaseEntity(delivery))
+}
+
+func subscriptionNotFound(subscriptionID string) error {
+	return util.NewNotFoundError("subscription_not_found", fmt.Sprintf("Webhook subscription %s not found", subscriptionID))
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+)
+
+// IdempotencyRecord is the result of the first request sent with an Idempotency-Key,
+// returned again when the client retries the request with the same key
+type IdempotencyRecord struct {
+	// Key is the client ID and the Idempotency-Key, keys of different clients never collide
+	Key         string `json:"idempotency_key"`
+	RequestHash string `json:"request_hash"`
+	// StatusCode is 0 while the first request is still being processed
+	StatusCode int `json:"status_code"`
+	// ContentType is the Content-Type of the response, problem details are not application/json
+	ContentType string    `json:"content_type"`
+	Response    string    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+	// ExpiresAt is the DynamoDB TTL attribute, in Unix seconds
+	ExpiresAt int64 `json:"expires_at"`
+	// LockedUntil is when a record that is still being processed is presumed abandoned, in Unix seconds.
+	// Past it, a retry takes the key over.
+	LockedUntil int64 `json:"locked_until"`
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// IsLocked reports whether the request processing the record may still complete it
+func (r *IdempotencyRecord) IsLocked(now time.Time) bool {
+	return !r.IsCompleted() && r.LockedUntil > now.Unix()
+}
+
+func (r *IdempotencyRecord) IsExpired(now time.Time) bool {
+	return r.ExpiresAt <= now.Unix()
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// KitchenQueue is what the kitchen has to prepare: the orders in preparation order and the items they add up to
+type KitchenQueue struct {
+	Orders []KitchenOrder
+	Items  []KitchenItem
+}
+
+// KitchenOrder is a queued order, waiting in the kitchen since ReceivedAt
+type KitchenOrder struct {
+	Order      Order
+	ReceivedAt time.Time
+	Elapsed    time.Duration
+}
+
+// KitchenItem is the quantity of an item to prepare across the queued orders
+type KitchenItem struct {
+	ItemID   string
+	Name     string
+	Quantity int
+	Orders   int
+}
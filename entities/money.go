@@ -0,0 +1,11 @@
+package entities
+
+import "math"
+
+// Cents is an amount of money in cents, so totals are computed without float rounding errors
+type Cents int64
+
+// CentsFromFloat converts a decimal price (e.g. 10.99) into cents
+func CentsFromFloat(value float64) Cents {
+	return Cents(math.Round(value * 100))
+}
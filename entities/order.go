@@ -1,21 +1,84 @@
 package entities
 
 import (
-	"golang.org/x/exp/slices"
+	"time"
 )
 
 type Order struct {
-	OrderID      string        `json:"order_id"`
-	ClientID     string        `json:"client_id"`
-	Status       Status        `json:"status"`
-	OrderedItems []OrderedItem `json:"ordered_items"`
-	Notes        string        `json:"notes"`
-	CreatedAt    string        `json:"created_at"`
-	UpdatedAt    string        `json:"updated_at"`
+	OrderID       string         `json:"order_id"`
+	ClientID      string         `json:"client_id"`
+	Status        Status         `json:"status"`
+	OrderedItems  []OrderedItem  `json:"ordered_items"`
+	Notes         string         `json:"notes"`
+	Subtotal      Cents          `json:"subtotal_cents"`
+	Discount      Cents          `json:"discount_cents"`
+	Total         Cents          `json:"total_cents"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	StatusHistory []StatusChange `json:"status_history"`
+	Version       int            `json:"version"`
+	DeletedAt     *time.Time     `json:"deleted_at,omitempty"`
+	DeletedBy     string         `json:"deleted_by,omitempty"`
+	// Station is the kitchen station preparing the order, set when the station claims it
+	Station   string     `json:"station,omitempty"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+	// PendingEvents are written to the outbox in the same transaction as the order
+	PendingEvents []OutboxEvent `json:"-" dynamodbav:"-"`
+}
+
+// IsDeleted reports whether the order was soft deleted
+func (p *Order) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// IsClaimed reports whether a kitchen station claimed the order
+func (p *Order) IsClaimed() bool {
+	return p.Station != ""
+}
+
+// ReceivedAt is when the order last reached the kitchen, its creation time when it never did
+func (p *Order) ReceivedAt() time.Time {
+	for i := len(p.StatusHistory) - 1; i >= 0; i-- {
+		if p.StatusHistory[i].To == ReceivedOrderStatus {
+			return p.StatusHistory[i].ChangedAt
+		}
+	}
+	return p.CreatedAt
 }
 
 func (p *Order) IsStatusValid() bool {
-	status := []Status{CreatedOrdersStatus, ReceivedOrderStatus, CookingOrderStatus, ReadyOrderStatus,
-		DeliveredOrderStatus, DoneOrderStatus, ApprovedPaymentOrderStatus, DeclinedPaymentOrderStatus}
-	return slices.Contains(status, p.Status)
+	return p.Status.IsValid()
+}
+
+func (p *Order) CanTransitionTo(status Status) bool {
+	return p.Status.CanTransitionTo(status)
+}
+
+func (p *Order) NextStatuses() []Status {
+	return p.Status.NextStatuses()
+}
+
+// CalculateTotals computes the line totals, the subtotal and the grand total (subtotal minus discount)
+func (p *Order) CalculateTotals() {
+	p.Subtotal = 0
+	for i := range p.OrderedItems {
+		p.Subtotal += p.OrderedItems[i].CalculateLineTotal()
+	}
+	p.Total = p.Subtotal - p.Discount
+}
+
+// ChangeStatus moves the order to status, recording the change in the status history.
+// Nothing is recorded when the status is kept.
+func (p *Order) ChangeStatus(status Status, actor, reason string, changedAt time.Time) {
+	if p.Status == status {
+		return
+	}
+	p.StatusHistory = append(p.StatusHistory, StatusChange{
+		From:      p.Status,
+		To:        status,
+		ChangedAt: changedAt,
+		Actor:     actor,
+		Reason:    reason,
+	})
+	p.Status = status
 }
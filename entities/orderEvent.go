@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+)
+
+// EventType of an order domain event
+type EventType string
+
+const (
+	OrderCreatedEvent       EventType = "OrderCreated"
+	OrderStatusChangedEvent EventType = "OrderStatusChanged"
+	OrderUpdatedEvent       EventType = "OrderUpdated"
+	OrderCancelledEvent     EventType = "OrderCancelled"
+	OrderDeletedEvent       EventType = "OrderDeleted"
+	OrderRestoredEvent      EventType = "OrderRestored"
+	OrderClaimedEvent       EventType = "OrderClaimed"
+)
+
+// OrderEventSchemaVersion is bumped on breaking changes of the event payload
+const OrderEventSchemaVersion = 1
+
+// OrderEvent is the envelope of every event published on the order queue.
+// CorrelationID is the order ID, so consumers can group every event of the same order.
+type OrderEvent struct {
+	EventID       string         `json:"event_id"`
+	Type          EventType      `json:"type"`
+	SchemaVersion int            `json:"schema_version"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	CorrelationID string         `json:"correlation_id"`
+	Data          OrderEventData `json:"data"`
+}
+
+type OrderEventData struct {
+	Order        Order         `json:"order"`
+	StatusChange *StatusChange `json:"status_change,omitempty"`
+}
@@ -0,0 +1,17 @@
+package entities
+
+// OrderField names a group of order attributes changed by a partial update
+type OrderField string
+
+const (
+	// OrderStatusField is the status and its history
+	OrderStatusField OrderField = "status"
+	// OrderNotesField is the order notes
+	OrderNotesField OrderField = "notes"
+	// OrderItemsField is the ordered items and the order totals
+	OrderItemsField OrderField = "ordered_items"
+	// OrderDeletionField is the soft deletion mark, deleted_at and deleted_by
+	OrderDeletionField OrderField = "deleted_at"
+	// OrderClaimField is the kitchen station that claimed the order and when, station and claimed_at
+	OrderClaimField OrderField = "station"
+)
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// OrderFilter selects a page of orders. Zero values do not filter.
+type OrderFilter struct {
+	ClientID string
+	// Statuses lists the accepted statuses, any status when empty
+	Statuses []Status
+	// CreatedFrom and CreatedTo bound the creation time, both inclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// ItemID keeps the orders containing this item
+	ItemID string
+	// Descending lists the newest orders first, oldest first otherwise
+	Descending     bool
+	IncludeDeleted bool
+	Limit          int
+	Cursor         string
+}
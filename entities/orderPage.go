@@ -0,0 +1,7 @@
+package entities
+
+// OrderPage is a page of a order listing. NextCursor is empty on the last page.
+type OrderPage struct {
+	Orders     []Order
+	NextCursor string
+}
@@ -1,15 +1,73 @@
 package entities
 
+import (
+	"golang.org/x/exp/slices"
+)
+
 // Status Order
 type Status string
 
 const (
 	CreatedOrdersStatus        Status = "CRIADO"
-	ReceivedOrderStatus               = "RECEBIDO"
-	CookingOrderStatus                = "EM_PREPARACAO"
-	ReadyOrderStatus                  = "PRONTO"
-	DeliveredOrderStatus              = "ENTREGUE"
-	DoneOrderStatus                   = "FINALIZADO"
-	ApprovedPaymentOrderStatus        = "APROVADO"
-	DeclinedPaymentOrderStatus        = "NEGADO"
+	ReceivedOrderStatus        Status = "RECEBIDO"
+	CookingOrderStatus         Status = "EM_PREPARACAO"
+	ReadyOrderStatus           Status = "PRONTO"
+	DeliveredOrderStatus       Status = "ENTREGUE"
+	DoneOrderStatus            Status = "FINALIZADO"
+	ApprovedPaymentOrderStatus Status = "APROVADO"
+	DeclinedPaymentOrderStatus Status = "NEGADO"
+	CanceledOrderStatus        Status = "CANCELADO"
 )
+
+// statusTransitions is the order lifecycle: every status maps to the statuses it may move to.
+// Statuses mapped to an empty list are terminal.
+var statusTransitions = map[Status][]Status{
+	CreatedOrdersStatus:        {ApprovedPaymentOrderStatus, DeclinedPaymentOrderStatus, CanceledOrderStatus},
+	ApprovedPaymentOrderStatus: {ReceivedOrderStatus, CanceledOrderStatus},
+	DeclinedPaymentOrderStatus: {CanceledOrderStatus},
+	ReceivedOrderStatus:        {CookingOrderStatus, CanceledOrderStatus},
+	CookingOrderStatus:         {ReadyOrderStatus},
+	ReadyOrderStatus:           {DeliveredOrderStatus},
+	DeliveredOrderStatus:       {DoneOrderStatus},
+	DoneOrderStatus:            {},
+	CanceledOrderStatus:        {},
+}
+
+// editableStatuses are the statuses in which the items and notes of an order may change:
+// before the payment is approved, or after it is declined so the client can fix the order
+var editableStatuses = []Status{CreatedOrdersStatus, DeclinedPaymentOrderStatus}
+
+// IsValid reports whether the status is part of the order lifecycle
+func (s Status) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// IsEditable reports whether the items and notes of an order in this status may still change
+func (s Status) IsEditable() bool {
+	return slices.Contains(editableStatuses, s)
+}
+
+// NextStatuses returns the statuses an order in this status may move to
+func (s Status) NextStatuses() []Status {
+	return slices.Clone(statusTransitions[s])
+}
+
+// CanTransitionTo reports whether an order may move from this status to next.
+// Keeping the current status is always allowed for valid statuses.
+func (s Status) CanTransitionTo(next Status) bool {
+	if !s.IsValid() || !next.IsValid() {
+		return false
+	}
+	return s == next || slices.Contains(statusTransitions[s], next)
+}
+
+// AllStatuses returns every status of the order lifecycle, sorted by name
+func AllStatuses() []Status {
+	statuses := make([]Status, 0, len(statusTransitions))
+	for status := range statusTransitions {
+		statuses = append(statuses, status)
+	}
+	slices.Sort(statuses)
+	return statuses
+}
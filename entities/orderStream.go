@@ -0,0 +1,37 @@
+package entities
+
+import "golang.org/x/exp/slices"
+
+// StreamFilter selects the order events sent to a stream. Zero values do not filter.
+type StreamFilter struct {
+	OrderID  string
+	ClientID string
+	// Statuses lists the accepted statuses of the order after the event, any status when empty
+	Statuses []Status
+}
+
+// Matches reports whether the events of the order belong to the stream
+func (f StreamFilter) Matches(order *Order) bool {
+	if f.OrderID != "" && order.OrderID != f.OrderID {
+		return false
+	}
+	if f.ClientID != "" && order.ClientID != f.ClientID {
+		return false
+	}
+	return len(f.Statuses) == 0 || slices.Contains(f.Statuses, order.Status)
+}
+
+// StreamedEvent is an order event numbered by the broadcaster, so streams can resume after the last event they got
+type StreamedEvent struct {
+	ID    uint64
+	Event OrderEvent
+}
+
+// OrderSubscription receives the events matching a stream filter. Replay holds the events published
+// since the one the subscriber resumes after, Events the following ones. Events is closed when the
+// subscriber falls too far behind, Close must be called once the subscriber is done.
+type OrderSubscription struct {
+	Replay []StreamedEvent
+	Events <-chan StreamedEvent
+	Close  func()
+}
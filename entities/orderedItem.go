@@ -7,4 +7,10 @@ type OrderedItem struct {
 	Name        string  `json:"name"`
 	Category    string  `json:"category"`
 	Description string  `json:"description"`
+	LineTotal   Cents   `json:"line_total_cents"`
+}
+
+func (i *OrderedItem) CalculateLineTotal() Cents {
+	i.LineTotal = CentsFromFloat(i.Price) * Cents(i.Quantity)
+	return i.LineTotal
 }
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+)
+
+// OutboxStatus of an event waiting to be published on the queue
+type OutboxStatus string
+
+const (
+	PendingOutboxStatus OutboxStatus = "PENDING"
+	SentOutboxStatus    OutboxStatus = "SENT"
+	FailedOutboxStatus  OutboxStatus = "FAILED"
+)
+
+// OutboxEvent is a message stored together with the order change that produced it,
+// so it is published on the queue even if the queue is down when the order is saved
+type OutboxEvent struct {
+	EventID       string       `json:"event_id"`
+	OrderID       string       `json:"order_id"`
+	Event         OrderEvent   `json:"event"`
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error"`
+	CreatedAt     time.Time    `json:"created_at"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	SentAt        time.Time    `json:"sent_at"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+// PaymentResult is the message published by the payments service once a payment is processed
+type PaymentResult struct {
+	OrderID   string `json:"order_id"`
+	PaymentID string `json:"payment_id"`
+	Status    Status `json:"status"`
+}
+
+// IsValid reports whether the result refers to an order and approves or declines its payment
+func (p *PaymentResult) IsValid() bool {
+	return p.OrderID != "" && (p.Status == ApprovedPaymentOrderStatus || p.Status == DeclinedPaymentOrderStatus)
+}
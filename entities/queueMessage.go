@@ -0,0 +1,8 @@
+package entities
+
+// QueueMessage is a message received from a queue. ReceiptHandle identifies the delivery to delete it.
+type QueueMessage struct {
+	MessageID     string
+	Body          string
+	ReceiptHandle string
+}
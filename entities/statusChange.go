@@ -0,0 +1,14 @@
+package entities
+
+import (
+	"time"
+)
+
+// StatusChange is an entry of the order status history
+type StatusChange struct {
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason"`
+}
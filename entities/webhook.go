@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// WebhookEventTypes are the order events sent to webhooks: the status changes
+var WebhookEventTypes = []EventType{OrderStatusChangedEvent, OrderCancelledEvent}
+
+// WebhookSubscription is a partner URL called with the order status changes
+type WebhookSubscription struct {
+	SubscriptionID string `json:"subscription_id"`
+	URL            string `json:"url"`
+	// EventTypes lists the events sent to the URL, every webhook event when empty
+	EventTypes []EventType `json:"event_types"`
+	// Statuses lists the statuses of the order after the event, any status when empty
+	Statuses []Status `json:"statuses"`
+	// Secret signs the deliveries, it is never returned by the API
+	Secret    string    `json:"secret"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Accepts reports whether the event must be delivered to the subscription
+func (s *WebhookSubscription) Accepts(event *OrderEvent) bool {
+	if !s.Active || !slices.Contains(WebhookEventTypes, event.Type) {
+		return false
+	}
+	if len(s.EventTypes) > 0 && !slices.Contains(s.EventTypes, event.Type) {
+		return false
+	}
+	return len(s.Statuses) == 0 || slices.Contains(s.Statuses, event.Data.Order.Status)
+}
+
+// DeliveryStatus of a webhook delivery
+type DeliveryStatus string
+
+const (
+	PendingDeliveryStatus   DeliveryStatus = "PENDING"
+	DeliveredDeliveryStatus DeliveryStatus = "DELIVERED"
+	FailedDeliveryStatus    DeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription, along with the result of the last attempt
+type WebhookDelivery struct {
+	DeliveryID     string         `json:"delivery_id"`
+	SubscriptionID string         `json:"subscription_id"`
+	EventID        string         `json:"event_id"`
+	EventType      EventType      `json:"event_type"`
+	Payload        string         `json:"payload"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	ResponseStatus int            `json:"response_status"`
+	LastError      string         `json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+	LastAttemptAt  time.Time      `json:"last_attempt_at"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	DeliveredAt    time.Time      `json:"delivered_at"`
+}
@@ -7,60 +7,127 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
 )
 
-func GetDynamoDbClient() *dynamodb.DynamoDB {
+// GetDynamoDbClient connects to DynamoDB. Local runs connect to the configured endpoint and create the tables.
+func GetDynamoDbClient(cfg *config.Config) *dynamodb.DynamoDB {
+	awsConfig := &aws.Config{
+		Region: aws.String(cfg.AWS.Region),
+	}
+	if cfg.DynamoDB.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(cfg.DynamoDB.Endpoint)
+	}
+
 	// Create a new AWS session
-	if os.Getenv("IS_LOCAL") == "true" {
-		sess, err := session.NewSession(&aws.Config{
-			Region:   aws.String("us-east-1"),
-			Endpoint: aws.String("http://localhost:9000"),
-		})
-		if err != nil {
-			fmt.Println("Error creating session:", err)
-			os.Exit(1)
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		fmt.Println("Error creating session:", err)
+		os.Exit(1)
+	}
+
+	// Create DynamoDB client
+	svc := dynamodb.New(sess)
+	if cfg.AWS.Local {
+		tables := cfg.DynamoDB.Tables
+		CreateOrderTables(svc, tables.Orders, tables.OrdersOutbox)
+		createLocalTable(svc, tables.Items, "item_id")
+		createLocalTable(svc, tables.IdempotencyKeys, "idempotency_key")
+		createLocalTable(svc, tables.WebhookSubscriptions, "subscription_id")
+		createLocalTable(svc, tables.WebhookDeliveries, "delivery_id",
+			localIndex{name: "StatusNextAttemptAtIndex", hashKey: "status", rangeKey: "next_attempt_at"},
+			localIndex{name: "SubscriptionCreatedAtIndex", hashKey: "subscription_id", rangeKey: "created_at"})
+		enableLocalTTL(svc, tables.IdempotencyKeys, "expires_at")
+		return svc
+	}
+
+	fmt.Printf("DynamoDB Connected: %v\n", svc)
+	return svc
+}
+
+// CreateOrderTables creates the orders table and its outbox table, with the indexes the order gateway queries.
+// Tables that already exist are left as they are.
+func CreateOrderTables(svc *dynamodb.DynamoDB, ordersTable, outboxTable string) {
+	createLocalTable(svc, ordersTable, "order_id",
+		localIndex{name: "ClientIdCreatedAtIndex", hashKey: "client_id", rangeKey: "created_at"},
+		localIndex{name: "StatusCreatedAtIndex", hashKey: "status", rangeKey: "created_at"})
+	createLocalTable(svc, outboxTable, "event_id",
+		localIndex{name: "StatusNextAttemptAtIndex", hashKey: "status", rangeKey: "next_attempt_at"})
+}
+
+type localIndex struct {
+	name     string
+	hashKey  string
+	rangeKey string
+}
+
+func createLocalTable(svc *dynamodb.DynamoDB, tableName, hashKey string, indexes ...localIndex) {
+	attributes := map[string]bool{hashKey: true}
+	var globalIndexes []*dynamodb.GlobalSecondaryIndex
+	for _, index := range indexes {
+		keySchema := []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String(index.hashKey), KeyType: aws.String("HASH")},
+		}
+		attributes[index.hashKey] = true
+		if index.rangeKey != "" {
+			keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+				AttributeName: aws.String(index.rangeKey), KeyType: aws.String("RANGE"),
+			})
+			attributes[index.rangeKey] = true
 		}
 
-		// Create DynamoDB client
-		svc := dynamodb.New(sess)
-		_, err = svc.CreateTable(&dynamodb.CreateTableInput{
-			TableName: aws.String("orders"),
-			AttributeDefinitions: []*dynamodb.AttributeDefinition{
-				{
-					AttributeName: aws.String("order_id"),
-					AttributeType: aws.String("S"),
-				},
-			},
-			KeySchema: []*dynamodb.KeySchemaElement{
-				{
-					AttributeName: aws.String("order_id"),
-					KeyType:       aws.String("HASH"),
-				},
-			},
+		globalIndexes = append(globalIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.name),
+			KeySchema:  keySchema,
+			Projection: &dynamodb.Projection{ProjectionType: aws.String("ALL")},
 			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
 				ReadCapacityUnits:  aws.Int64(5),
 				WriteCapacityUnits: aws.Int64(5),
 			},
 		})
+	}
 
-		if err != nil {
-			fmt.Println("Got error calling CreateTable:")
-			fmt.Println(err.Error())
-		}
-
-		return svc
+	var attributeDefinitions []*dynamodb.AttributeDefinition
+	for attribute := range attributes {
+		attributeDefinitions = append(attributeDefinitions, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(attribute),
+			AttributeType: aws.String("S"),
+		})
 	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("us-east-1"),
+	_, err := svc.CreateTable(&dynamodb.CreateTableInput{
+		TableName:            aws.String(tableName),
+		AttributeDefinitions: attributeDefinitions,
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(hashKey),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		GlobalSecondaryIndexes: globalIndexes,
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
 	})
+
 	if err != nil {
-		fmt.Println("Error creating session:", err)
-		os.Exit(1)
+		fmt.Printf("Got error calling CreateTable %s:\n", tableName)
+		fmt.Println(err.Error())
 	}
+}
 
-	// Create DynamoDB client
-	svc := dynamodb.New(sess)
-	fmt.Printf("DynamoDB Connected: %v\n", svc)
-	return svc
+func enableLocalTTL(svc *dynamodb.DynamoDB, tableName, attribute string) {
+	_, err := svc.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(attribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+
+	if err != nil {
+		fmt.Printf("Got error calling UpdateTimeToLive %s:\n", tableName)
+		fmt.Println(err.Error())
+	}
 }
@@ -5,17 +5,22 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
-	"os"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
 )
 
-func GetSqsClient() *sqs.SQS {
-	if os.Getenv("IS_LOCAL") == "true" {
-
+// GetSqsClient connects to SQS, at the configured endpoint when there is one.
+// Local runs without an endpoint get no client, so they never send messages to AWS.
+func GetSqsClient(cfg *config.Config) *sqs.SQS {
+	if !cfg.UsesQueues() {
+		fmt.Println("SQS_ENDPOINT is not set, messages are recorded in memory")
+		return nil
 	}
 
-	region := "us-east-1"
 	awsConfig := &aws.Config{
-		Region: aws.String(region),
+		Region: aws.String(cfg.AWS.Region),
+	}
+	if cfg.Queues.SQSEndpoint != "" {
+		awsConfig.Endpoint = aws.String(cfg.Queues.SQSEndpoint)
 	}
 
 	sqsClient := sqs.New(session.Must(session.NewSession(awsConfig)))
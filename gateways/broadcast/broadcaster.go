@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"sync"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+const (
+	// defaultHistorySize is how many events are kept to resume streams
+	defaultHistorySize = 1000
+	// subscriberBufferSize is how many events a subscriber may fall behind before it is dropped
+	subscriberBufferSize = 64
+)
+
+// Broadcaster sends the order events published in this process to the subscribed streams.
+// It keeps the last events, so a stream that reconnects can resume after the last event it got.
+type Broadcaster struct {
+	mutex       sync.Mutex
+	lastID      uint64
+	history     []entities.StreamedEvent
+	historySize int
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	filter entities.StreamFilter
+	events chan entities.StreamedEvent
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		historySize: defaultHistorySize,
+		subscribers: map[*subscriber]bool{},
+	}
+}
+
+// Publish numbers the event and sends it to the subscribers of the order. A subscriber whose buffer is full
+// is dropped instead of blocking the publisher, its stream resumes from the history when it reconnects.
+func (b *Broadcaster) Publish(event entities.OrderEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastID++
+	streamed := entities.StreamedEvent{ID: b.lastID, Event: event}
+	b.history = append(b.history, streamed)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for s := range b.subscribers {
+		if !s.filter.Matches(&event.Data.Order) {
+			continue
+		}
+		select {
+		case s.events <- streamed:
+		default:
+			delete(b.subscribers, s)
+			close(s.events)
+		}
+	}
+}
+
+// Subscribe starts receiving the events matching the filter. When lastEventID is not zero, the events
+// published after it that are still kept are replayed.
+func (b *Broadcaster) Subscribe(filter entities.StreamFilter, lastEventID uint64) *entities.OrderSubscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var replay []entities.StreamedEvent
+	if lastEventID != 0 {
+		for _, streamed := range b.history {
+			if streamed.ID > lastEventID && filter.Matches(&streamed.Event.Data.Order) {
+				replay = append(replay, streamed)
+			}
+		}
+	}
+
+	s := &subscriber{filter: filter, events: make(chan entities.StreamedEvent, subscriberBufferSize)}
+	b.subscribers[s] = true
+
+	return &entities.OrderSubscription{
+		Replay: replay,
+		Events: s.events,
+		Close:  func() { b.unsubscribe(s) },
+	}
+}
+
+func (b *Broadcaster) unsubscribe(s *subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.subscribers[s] {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
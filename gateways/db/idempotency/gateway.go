@@ -0,0 +1,168 @@
+package idempotency
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+type Gateway struct {
+	tableName  string
+	repository *dynamodb.DynamoDB
+}
+
+// NewGateway returns the gateway of the idempotency keys table
+func NewGateway(repository *dynamodb.DynamoDB, tableName string) *Gateway {
+	return &Gateway{
+		tableName:  tableName,
+		repository: repository,
+	}
+}
+
+// Create writes the record only if its key is not in use. DynamoDB deletes expired items lazily,
+// so a key whose record expired by now counts as free. It returns false when the key is in use.
+func (g *Gateway) Create(record *entities.IdempotencyRecord, now time.Time) (bool, error) {
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return false, err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName:           &g.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(idempotency_key) OR expires_at <= :now"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+		},
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			return false, nil
+		}
+		fmt.Printf("Error saving idempotency key %s: %s\n", record.Key, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (g *Gateway) GetByKey(key string) (*entities.IdempotencyRecord, error) {
+	result, err := g.repository.GetItem(&dynamodb.GetItemInput{
+		TableName:      &g.tableName,
+		Key:            map[string]*dynamodb.AttributeValue{"idempotency_key": {S: aws.String(key)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		fmt.Printf("Error fetching idempotency key %s: %s\n", key, err)
+		return nil, err
+	}
+
+	if len(result.Item) == 0 {
+		return nil, nil
+	}
+
+	var record entities.IdempotencyRecord
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &record); err != nil {
+		fmt.Printf("Error Unmarshalling idempotency key: %s\nerror: %s", key, err)
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// TakeOver replaces a record that is still being processed and still locked until lockedUntil, that is
+// one no other request completed or took over since it was read. It returns false when the record changed.
+func (g *Gateway) TakeOver(record *entities.IdempotencyRecord, lockedUntil int64) (bool, error) {
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return false, err
+	}
+
+	// Records written before the lock existed have no locked_until
+	lockCondition := "locked_until = :locked_until"
+	if lockedUntil == 0 {
+		lockCondition = "(attribute_not_exists(locked_until) OR locked_until = :locked_until)"
+	}
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName:           &g.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("status_code = :processing AND " + lockCondition),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":processing":   {N: aws.String("0")},
+			":locked_until": {N: aws.String(strconv.FormatInt(lockedUntil, 10))},
+		},
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			return false, nil
+		}
+		fmt.Printf("Error taking over idempotency key %s: %s\n", record.Key, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Complete stores the response of the record, only while the request that wrote it still holds the key,
+// that is while the stored record is still being processed under the lock of the record.
+// It returns false when the key was taken over.
+func (g *Gateway) Complete(record *entities.IdempotencyRecord) (bool, error) {
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return false, err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName:                 &g.tableName,
+		Item:                      item,
+		ConditionExpression:       aws.String(heldCondition),
+		ExpressionAttributeValues: heldValues(record),
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			return false, nil
+		}
+		fmt.Printf("Error saving idempotency key %s: %s\n", record.Key, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Release deletes the record, only while the request that wrote it still holds the key.
+// It returns false when the key was taken over.
+func (g *Gateway) Release(record *entities.IdempotencyRecord) (bool, error) {
+	_, err := g.repository.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 &g.tableName,
+		Key:                       map[string]*dynamodb.AttributeValue{"idempotency_key": {S: aws.String(record.Key)}},
+		ConditionExpression:       aws.String(heldCondition),
+		ExpressionAttributeValues: heldValues(record),
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			return false, nil
+		}
+		fmt.Printf("Error deleting idempotency key %s: %s\n", record.Key, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// heldCondition holds while the stored record is the one a request reserved or took over and is still processing
+const heldCondition = "status_code = :processing AND locked_until = :locked_until"
+
+func heldValues(record *entities.IdempotencyRecord) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		":processing":   {N: aws.String("0")},
+		":locked_until": {N: aws.String(strconv.FormatInt(record.LockedUntil, 10))},
+	}
+}
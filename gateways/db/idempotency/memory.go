@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// MemoryGateway keeps the idempotency records in memory, for local runs and tests
+type MemoryGateway struct {
+	mu      sync.Mutex
+	records map[string]entities.IdempotencyRecord
+}
+
+func NewMemoryGateway() *MemoryGateway {
+	return &MemoryGateway{records: map[string]entities.IdempotencyRecord{}}
+}
+
+// Create writes the record only if its key is not in use or its record expired by now.
+// It returns false when the key is in use.
+func (g *MemoryGateway) Create(record *entities.IdempotencyRecord, now time.Time) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if stored, exists := g.records[record.Key]; exists && !stored.IsExpired(now) {
+		return false, nil
+	}
+
+	g.records[record.Key] = *record
+	return true, nil
+}
+
+func (g *MemoryGateway) GetByKey(key string) (*entities.IdempotencyRecord, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	record, exists := g.records[key]
+	if !exists {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// TakeOver replaces a record that is still being processed and still locked until lockedUntil, that is
+// one no other request completed or took over since it was read. It returns false when the record changed.
+func (g *MemoryGateway) TakeOver(record *entities.IdempotencyRecord, lockedUntil int64) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, exists := g.records[record.Key]
+	if !exists || stored.IsCompleted() || stored.LockedUntil != lockedUntil {
+		return false, nil
+	}
+
+	g.records[record.Key] = *record
+	return true, nil
+}
+
+// Complete stores the response of the record, only while the request that wrote it still holds the key.
+// It returns false when the key was taken over.
+func (g *MemoryGateway) Complete(record *entities.IdempotencyRecord) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.holds(record) {
+		return false, nil
+	}
+	g.records[record.Key] = *record
+	return true, nil
+}
+
+// Release deletes the record, only while the request that wrote it still holds the key.
+// It returns false when the key was taken over.
+func (g *MemoryGateway) Release(record *entities.IdempotencyRecord) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.holds(record) {
+		return false, nil
+	}
+	delete(g.records, record.Key)
+	return true, nil
+}
+
+// holds reports whether the stored record is the one the request of record reserved and is still processing
+func (g *MemoryGateway) holds(record *entities.IdempotencyRecord) bool {
+	stored, exists := g.records[record.Key]
+	return exists && !stored.IsCompleted() && stored.LockedUntil == record.LockedUntil
+}
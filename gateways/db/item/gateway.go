@@ -0,0 +1,104 @@
+package item
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+type Gateway struct {
+	tableName  string
+	repository *dynamodb.DynamoDB
+}
+
+// NewGateway returns the gateway of the items table
+func NewGateway(repository *dynamodb.DynamoDB, tableName string) *Gateway {
+	return &Gateway{
+		tableName:  tableName,
+		repository: repository,
+	}
+}
+
+func (g *Gateway) Save(item *entities.Item) (*entities.Item, error) {
+
+	//Marshaling item to a DynamoDB MAP
+	attributes, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return nil, err
+	}
+
+	//Saving Input Item
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName: &g.tableName,
+		Item:      attributes,
+	})
+	if err != nil {
+		fmt.Println("Error inserting item:", err)
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (g *Gateway) GetByID(itemID string) (*entities.Item, error) {
+
+	//Creating a DynamoDB Query Input Search by Key (item id)
+	fetch := &dynamodb.QueryInput{
+		TableName:              &g.tableName,
+		KeyConditionExpression: aws.String("item_id = :item_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":item_id": {
+				S: aws.String(itemID),
+			},
+		},
+	}
+
+	// Fetching the Item using query
+	result, err := g.repository.Query(fetch)
+	if err != nil {
+		fmt.Printf("Error fetching item ID: %s\nerror: %s", itemID, err)
+		return nil, err
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var items []entities.Item
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		fmt.Printf("Error Unmarshalling item ID: %s\nerror: %s", itemID, err)
+		return nil, err
+	}
+
+	return &items[0], nil
+}
+
+func (g *Gateway) GetAll() (*[]entities.Item, error) {
+	items := []entities.Item{}
+
+	// Scanning every page of the table
+	var unmarshalErr error
+	err := g.repository.ScanPages(&dynamodb.ScanInput{TableName: &g.tableName},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var pageItems []entities.Item
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageItems); unmarshalErr != nil {
+				return false
+			}
+			items = append(items, pageItems...)
+			return true
+		})
+	if err != nil {
+		fmt.Printf("Error scanning table %s - Error: %s", g.tableName, err)
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.tableName, unmarshalErr)
+		return nil, unmarshalErr
+	}
+
+	return &items, nil
+}
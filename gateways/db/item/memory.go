@@ -0,0 +1,53 @@
+package item
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// MemoryGateway keeps the items in memory, for local runs and tests
+type MemoryGateway struct {
+	mu    sync.RWMutex
+	items map[string]entities.Item
+}
+
+func NewMemoryGateway() *MemoryGateway {
+	return &MemoryGateway{items: map[string]entities.Item{}}
+}
+
+func (g *MemoryGateway) Save(item *entities.Item) (*entities.Item, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.items[item.ItemID] = *item
+	return item, nil
+}
+
+func (g *MemoryGateway) GetByID(itemID string) (*entities.Item, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	item, exists := g.items[itemID]
+	if !exists {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+// GetAll returns the items sorted by ID
+func (g *MemoryGateway) GetAll() (*[]entities.Item, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	items := []entities.Item{}
+	for _, item := range g.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ItemID < items[j].ItemID
+	})
+
+	return &items, nil
+}
@@ -0,0 +1,102 @@
+package order
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+// listCursor is where a listing stopped in each of its queries. Queries missing from it were not started.
+type listCursor struct {
+	Queries map[string]queryPosition `json:"queries"`
+}
+
+// queryPosition is the ExclusiveStartKey of the next page of a query, Done when the query has no more pages
+type queryPosition struct {
+	Key  map[string]*dynamodb.AttributeValue `json:"key,omitempty"`
+	Done bool                                `json:"done,omitempty"`
+}
+
+// encodeCursor turns the query positions into an opaque cursor. It is empty when every query is done.
+func encodeCursor(queries []listQuery, positions map[string]queryPosition) (string, error) {
+	finished := true
+	for _, query := range queries {
+		finished = finished && positions[query.name].Done
+	}
+	if finished {
+		return "", nil
+	}
+
+	data, err := json.Marshal(listCursor{Queries: positions})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor turns a cursor created by encodeCursor back into query positions.
+// The cursor must belong to a listing made of the given queries.
+func decodeCursor(cursor string, queries []listQuery) (map[string]queryPosition, error) {
+	positions := map[string]queryPosition{}
+	if cursor == "" {
+		return positions, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, util.NewBadRequestError("invalid_cursor", "Invalid cursor")
+	}
+
+	var decoded listCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Queries) == 0 {
+		return nil, util.NewBadRequestError("invalid_cursor", "Invalid cursor")
+	}
+
+	names := map[string]bool{}
+	for _, query := range queries {
+		names[query.name] = true
+	}
+	for name, position := range decoded.Queries {
+		if !names[name] {
+			return nil, util.NewBadRequestError("invalid_cursor", "Cursor does not match the filters")
+		}
+		positions[name] = position
+	}
+
+	return positions, nil
+}
+
+// keysetQuery names the single position of the listings read in one sorted pass, by the memory and Postgres gateways
+const keysetQuery = "keyset"
+
+// encodeKeysetCursor turns the last order of a one pass listing page into a cursor
+func encodeKeysetCursor(orderID, createdAt string) (string, error) {
+	queries := []listQuery{{name: keysetQuery}}
+	return encodeCursor(queries, map[string]queryPosition{keysetQuery: {Key: map[string]*dynamodb.AttributeValue{
+		"order_id":   {S: aws.String(orderID)},
+		"created_at": {S: aws.String(createdAt)},
+	}}})
+}
+
+// decodeKeysetCursor returns the last order of the previous page of a one pass listing, empty strings when there is no cursor
+func decodeKeysetCursor(cursor string) (orderID, createdAt string, err error) {
+	positions, err := decodeCursor(cursor, []listQuery{{name: keysetQuery}})
+	if err != nil {
+		return "", "", err
+	}
+
+	position, started := positions[keysetQuery]
+	if !started {
+		return "", "", nil
+	}
+
+	lastOrderID, lastCreatedAt := position.Key["order_id"], position.Key["created_at"]
+	if lastOrderID == nil || lastCreatedAt == nil || aws.StringValue(lastOrderID.S) == "" {
+		return "", "", util.NewBadRequestError("invalid_cursor", "Invalid cursor")
+	}
+	return aws.StringValue(lastOrderID.S), aws.StringValue(lastCreatedAt.S), nil
+}
@@ -2,168 +2,286 @@ package order
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
 )
 
 type Gateway struct {
-	TableName  string
-	repository *dynamodb.DynamoDB
+	tableName       string
+	outboxTableName string
+	repository      *dynamodb.DynamoDB
 }
 
-func NewGateway(repository *dynamodb.DynamoDB) *Gateway {
+// NewGateway returns the gateway of the orders table, which writes the events of the orders to the outbox table
+func NewGateway(repository *dynamodb.DynamoDB, tableName, outboxTableName string) *Gateway {
 	return &Gateway{
-		TableName:  "orders",
-		repository: repository,
+		tableName:       tableName,
+		outboxTableName: outboxTableName,
+		repository:      repository,
 	}
 }
 
+// Save writes the order only if the stored version is still the one it was read with, bumping its version.
+// A new order (version 0) is only written if no versioned order with the same ID exists.
+// The order pending events are written to the outbox in the same transaction.
 func (g *Gateway) Save(order *entities.Order) (*entities.Order, error) {
+	expectedVersion := order.Version
+	order.Version = expectedVersion + 1
 
 	//Marshaling order to a DynamoDB MAP
-	item, err := dynamodbattribute.MarshalMap(order)
+	item, err := dynamodbattribute.MarshalMap(newOrderRecord(order))
 	if err != nil {
+		order.Version = expectedVersion
 		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
 		return nil, err
 	}
 
-	//Creating a DynamoDB Input Item
-	input := &dynamodb.PutItemInput{
-		TableName: &g.TableName,
-		Item:      item,
+	//Creating a DynamoDB Put conditioned on the version read
+	put := &dynamodb.Put{
+		TableName:           &g.tableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(version)"),
+	}
+	if expectedVersion > 0 {
+		put.ConditionExpression = aws.String("version = :version")
+		put.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":version": {N: aws.String(strconv.Itoa(expectedVersion))},
+		}
 	}
 
 	//Saving Input Item
-	_, err = g.repository.PutItem(input)
+	if len(order.PendingEvents) == 0 {
+		_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+			TableName:                 put.TableName,
+			Item:                      put.Item,
+			ConditionExpression:       put.ConditionExpression,
+			ExpressionAttributeValues: put.ExpressionAttributeValues,
+		})
+	} else {
+		err = g.writeWithEvents(&dynamodb.TransactWriteItem{Put: put}, order.PendingEvents)
+	}
 	if err != nil {
+		order.Version = expectedVersion
+		if failed, _ := conditionFailure(err); failed {
+			return nil, util.NewConflictError("concurrent_modification",
+				fmt.Sprintf("Order %s was modified by another request", order.OrderID))
+		}
 		fmt.Println("Error inserting item:", err)
 		return nil, err
 	}
 
+	order.PendingEvents = nil
 	fmt.Println("Item inserted successfully")
 	return order, nil
 }
 
-func (g *Gateway) Update(orderID string, order *entities.Order) (*entities.Order, error) {
-	return nil, nil
-}
-
-func (g *Gateway) Delete(order *entities.Order) error {
-
-	// Create the key parameter for the deleting operation
-	input := &dynamodb.DeleteItemInput{
-		TableName: &g.TableName,
-		Key: map[string]*dynamodb.AttributeValue{
-			"order_id": {
-				S: aws.String(order.OrderID),
+// writeWithEvents writes an order change and its outbox events in a single transaction
+func (g *Gateway) writeWithEvents(orderWrite *dynamodb.TransactWriteItem, events []entities.OutboxEvent) error {
+	transactItems := []*dynamodb.TransactWriteItem{orderWrite}
+	for _, event := range events {
+		eventItem, err := MarshalOutboxEvent(&event)
+		if err != nil {
+			return err
+		}
+		transactItems = append(transactItems, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:           &g.outboxTableName,
+				Item:                eventItem,
+				ConditionExpression: aws.String("attribute_not_exists(event_id)"),
 			},
-		},
+		})
 	}
 
-	// Deleting operation
-	_, err := g.repository.DeleteItem(input)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, err := g.repository.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	return err
 }
 
-func (g *Gateway) GetByID(orderID string) (*entities.Order, error) {
+// conditionFailure reports whether a write, or the order write of a transaction, failed its condition.
+// It also returns the item as it was stored, when the write asked for it.
+func conditionFailure(err error) (bool, map[string]*dynamodb.AttributeValue) {
+	switch e := err.(type) {
+	case *dynamodb.TransactionCanceledException:
+		reasons := e.CancellationReasons
+		if len(reasons) > 0 && aws.StringValue(reasons[0].Code) == "ConditionalCheckFailed" {
+			return true, reasons[0].Item
+		}
+	case *dynamodb.ConditionalCheckFailedException:
+		return true, e.Item
+	}
+	return false, nil
+}
 
-	//Creating a DynamoDB Query Input Search by Key (order id)
-	fetch := &dynamodb.QueryInput{
-		TableName:              &g.TableName,
-		KeyConditionExpression: aws.String("order_id = :order_id"),
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":order_id": {
-				S: aws.String(orderID),
-			},
-		},
+// Update changes only the given fields of an existing order, along with updated_at and version.
+// It returns nil when the order does not exist and a conflict error when it was modified since it was read.
+func (g *Gateway) Update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error) {
+	values := map[string]interface{}{
+		":updated_at":  formatTimestamp(order.UpdatedAt),
+		":new_version": order.Version + 1,
+	}
+	names := map[string]*string{"#version": aws.String("version")}
+	sets := []string{"updated_at = :updated_at", "#version = :new_version"}
+	var removes []string
+
+	for _, field := range fields {
+		switch field {
+		case entities.OrderStatusField:
+			names["#status"] = aws.String("status")
+			sets = append(sets, "#status = :status", "status_history = :status_history")
+			values[":status"] = order.Status
+			values[":status_history"] = newStatusHistoryRecord(order.StatusHistory)
+		case entities.OrderNotesField:
+			sets = append(sets, "notes = :notes")
+			values[":notes"] = order.Notes
+		case entities.OrderItemsField:
+			sets = append(sets, "ordered_items = :ordered_items", "item_ids = :item_ids", "subtotal_cents = :subtotal_cents",
+				"discount_cents = :discount_cents", "total_cents = :total_cents")
+			values[":ordered_items"] = order.OrderedItems
+			values[":item_ids"] = orderedItemIDs(order.OrderedItems)
+			values[":subtotal_cents"] = order.Subtotal
+			values[":discount_cents"] = order.Discount
+			values[":total_cents"] = order.Total
+		case entities.OrderDeletionField:
+			if order.IsDeleted() {
+				sets = append(sets, "deleted_at = :deleted_at", "deleted_by = :deleted_by")
+				values[":deleted_at"] = formatTimestamp(*order.DeletedAt)
+				values[":deleted_by"] = order.DeletedBy
+			} else {
+				removes = append(removes, "deleted_at", "deleted_by")
+			}
+		case entities.OrderClaimField:
+			if order.IsClaimed() && order.ClaimedAt != nil {
+				sets = append(sets, "station = :station", "claimed_at = :claimed_at")
+				values[":station"] = order.Station
+				values[":claimed_at"] = formatTimestamp(*order.ClaimedAt)
+			} else {
+				removes = append(removes, "station", "claimed_at")
+			}
+		}
 	}
 
-	// Fetching the Order using query
-	result, err := g.repository.Query(fetch)
-	if err != nil {
-		fmt.Printf("Error fetching order ID: %s\nerror: %s", orderID, err)
-		return nil, err
+	updateExpression := "SET " + strings.Join(sets, ", ")
+	if len(removes) > 0 {
+		updateExpression += " REMOVE " + strings.Join(removes, ", ")
 	}
 
-	if len(result.Items) == 0 {
-		fmt.Printf("Order ID: %s does not exist", orderID)
-		return nil, nil
+	condition := "attribute_exists(order_id) AND attribute_not_exists(#version)"
+	if order.Version > 0 {
+		condition = "attribute_exists(order_id) AND #version = :version"
+		values[":version"] = order.Version
 	}
 
-	// Unmarshalling the DynamoDB item into Orders
-	var orders []entities.Order
-	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &orders); err != nil {
-		fmt.Printf("Error Unmarshalling order ID: %s\nerror: %s", orderID, err)
+	attributeValues, err := dynamodbattribute.MarshalMap(values)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
 		return nil, err
 	}
 
-	return &orders[0], nil
-}
-
-func (g *Gateway) GetAll() (orders *[]entities.Order, err error) {
+	key := map[string]*dynamodb.AttributeValue{
+		"order_id": {
+			S: aws.String(orderID),
+		},
+	}
 
-	// Scanning the table
-	params := &dynamodb.ScanInput{
-		TableName: &g.TableName,
+	if len(order.PendingEvents) > 0 {
+		err = g.writeWithEvents(&dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName:                           &g.tableName,
+				Key:                                 key,
+				UpdateExpression:                    aws.String(updateExpression),
+				ConditionExpression:                 aws.String(condition),
+				ExpressionAttributeNames:            names,
+				ExpressionAttributeValues:           attributeValues,
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			},
+		}, order.PendingEvents)
+		if err != nil {
+			return g.updateFailed(orderID, err)
+		}
+
+		// Transactions do not return the new item, but the order holds every attribute written
+		order.Version++
+		order.PendingEvents = nil
+		return order, nil
 	}
 
-	// Perform Scan operation
-	result, err := g.repository.Scan(params)
+	result, err := g.repository.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                           &g.tableName,
+		Key:                                 key,
+		UpdateExpression:                    aws.String(updateExpression),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           attributeValues,
+		ReturnValues:                        aws.String(dynamodb.ReturnValueAllNew),
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	})
 	if err != nil {
-		fmt.Printf("Error scanning table %s - Error: %s", g.TableName, err)
-		return
+		return g.updateFailed(orderID, err)
 	}
 
-	if len(result.Items) == 0 {
-		fmt.Printf("Table %s is empty", g.TableName)
-		return orders, nil
+	var record orderRecord
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &record); err != nil {
+		fmt.Printf("Error Unmarshalling order ID: %s\nerror: %s", orderID, err)
+		return nil, err
 	}
 
-	// Unmarshalling the DynamoDB item into Orders
-	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &orders); err != nil {
-		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.TableName, err)
-		return nil, err
+	return record.toEntity()
+}
+
+// updateFailed tells a missing order (nil) from an order modified by another request (conflict error)
+func (g *Gateway) updateFailed(orderID string, err error) (*entities.Order, error) {
+	if failed, storedItem := conditionFailure(err); failed {
+		if len(storedItem) == 0 {
+			fmt.Printf("Order ID: %s does not exist", orderID)
+			return nil, nil
+		}
+		return nil, util.NewConflictError("concurrent_modification",
+			fmt.Sprintf("Order %s was modified by another request", orderID))
 	}
 
-	return orders, nil
+	fmt.Printf("Error updating order ID: %s\nerror: %s", orderID, err)
+	return nil, err
 }
 
-func (g *Gateway) GetAllByClientID(clientID string) (orders *[]entities.Order, err error) {
+// GetByID returns the order even when it was soft deleted
+func (g *Gateway) GetByID(orderID string) (*entities.Order, error) {
 
-	// Querying table by client_id - GSI
-	query := &dynamodb.QueryInput{
-		TableName:              &g.TableName,
-		IndexName:              aws.String("ClientIdIndex"),
-		KeyConditionExpression: aws.String("client_id = :client_id"),
+	//Creating a DynamoDB Query Input Search by Key (order id)
+	fetch := &dynamodb.QueryInput{
+		TableName:              &g.tableName,
+		KeyConditionExpression: aws.String("order_id = :order_id"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":client_id": {S: aws.String(clientID)},
+			":order_id": {
+				S: aws.String(orderID),
+			},
 		},
 	}
 
-	// Perform Query operation
-	result, err := g.repository.Query(query)
+	// Fetching the Order using query
+	result, err := g.repository.Query(fetch)
 	if err != nil {
-		fmt.Printf("Error scanning table %s - Error: %s", g.TableName, err)
-		return
+		fmt.Printf("Error fetching order ID: %s\nerror: %s", orderID, err)
+		return nil, err
 	}
 
 	if len(result.Items) == 0 {
-		fmt.Printf("Table %s is empty", g.TableName)
-		return orders, nil
+		fmt.Printf("Order ID: %s does not exist", orderID)
+		return nil, nil
 	}
 
 	// Unmarshalling the DynamoDB item into Orders
-	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &orders); err != nil {
-		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.TableName, err)
+	var records []orderRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &records); err != nil {
+		fmt.Printf("Error Unmarshalling order ID: %s\nerror: %s", orderID, err)
 		return nil, err
 	}
 
-	return orders, nil
+	return records[0].toEntity()
 }
@@ -0,0 +1,113 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	clientIndex = "ClientIdCreatedAtIndex"
+	statusIndex = "StatusCreatedAtIndex"
+)
+
+// listIndexes are the global secondary indexes GetAll queries, by name
+var listIndexes = []struct {
+	name    string
+	hashKey string
+}{
+	{name: clientIndex, hashKey: "client_id"},
+	{name: statusIndex, hashKey: "status"},
+}
+
+// indexPollInterval is how often EnsureIndexes checks whether a new index finished backfilling
+const indexPollInterval = 10 * time.Second
+
+// EnsureIndexes creates the indexes GetAll queries that the orders table does not have yet, one at a time,
+// waiting for every new index to finish backfilling. It returns the names of the indexes it created.
+// Tables created before the indexes existed only have the ClientIdIndex, which GetAll no longer uses.
+func (g *Gateway) EnsureIndexes() ([]string, error) {
+	var created []string
+	for _, index := range listIndexes {
+		table, err := g.describeTable()
+		if err != nil {
+			return created, err
+		}
+		if hasIndex(table, index.name) {
+			continue
+		}
+
+		create := &dynamodb.CreateGlobalSecondaryIndexAction{
+			IndexName: aws.String(index.name),
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String(index.hashKey), KeyType: aws.String("HASH")},
+				{AttributeName: aws.String("created_at"), KeyType: aws.String("RANGE")},
+			},
+			Projection: &dynamodb.Projection{ProjectionType: aws.String("ALL")},
+		}
+		// Provisioned tables need the capacity of the index, the table capacity is used
+		if table.BillingModeSummary == nil || aws.StringValue(table.BillingModeSummary.BillingMode) == dynamodb.BillingModeProvisioned {
+			create.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  table.ProvisionedThroughput.ReadCapacityUnits,
+				WriteCapacityUnits: table.ProvisionedThroughput.WriteCapacityUnits,
+			}
+		}
+
+		_, err = g.repository.UpdateTable(&dynamodb.UpdateTableInput{
+			TableName: &g.tableName,
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String(index.hashKey), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("created_at"), AttributeType: aws.String("S")},
+			},
+			GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{{Create: create}},
+		})
+		if err != nil {
+			fmt.Printf("Error creating index %s on table %s - Error: %s\n", index.name, g.tableName, err)
+			return created, err
+		}
+		created = append(created, index.name)
+
+		if err := g.waitForIndex(index.name); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+func (g *Gateway) describeTable() (*dynamodb.TableDescription, error) {
+	output, err := g.repository.DescribeTable(&dynamodb.DescribeTableInput{TableName: &g.tableName})
+	if err != nil {
+		fmt.Printf("Error describing table %s - Error: %s\n", g.tableName, err)
+		return nil, err
+	}
+	return output.Table, nil
+}
+
+// waitForIndex returns once the index is ACTIVE, that is once DynamoDB finished backfilling it
+func (g *Gateway) waitForIndex(indexName string) error {
+	for {
+		table, err := g.describeTable()
+		if err != nil {
+			return err
+		}
+		for _, index := range table.GlobalSecondaryIndexes {
+			if aws.StringValue(index.IndexName) == indexName &&
+				aws.StringValue(index.IndexStatus) == dynamodb.IndexStatusActive {
+				return nil
+			}
+		}
+		time.Sleep(indexPollInterval)
+	}
+}
+
+func hasIndex(table *dynamodb.TableDescription, indexName string) bool {
+	for _, index := range table.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) == indexName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package order
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// notDeletedFilter is the filter expression hiding soft deleted orders from queries
+const notDeletedFilter = "attribute_not_exists(deleted_at)"
+
+// listQuery is one of the index queries of a listing. Its results are sorted by created_at.
+type listQuery struct {
+	name  string
+	input *dynamodb.QueryInput
+	// keyAttributes are the attributes of an ExclusiveStartKey on the queried index
+	keyAttributes []string
+}
+
+// GetAll pages through the orders matching the filter, sorted by created_at.
+// A client listing queries the ClientIdCreatedAtIndex, any other listing queries the StatusCreatedAtIndex
+// once per status and merges the results. The creation time range is a key condition,
+// the other filters are filter expressions, so a page may hold fewer orders than the limit even if more follow.
+func (g *Gateway) GetAll(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	queries := g.listQueries(filter)
+
+	positions, err := decodeCursor(filter.Cursor, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []queryResult
+	for _, query := range queries {
+		position, started := positions[query.name]
+		if position.Done {
+			continue
+		}
+
+		input := *query.input
+		input.Limit = aws.Int64(int64(filter.Limit))
+		if started {
+			input.ExclusiveStartKey = position.Key
+		}
+
+		// Perform Query operation
+		output, err := g.repository.Query(&input)
+		if err != nil {
+			fmt.Printf("Error querying table %s - Error: %s", g.tableName, err)
+			return nil, err
+		}
+		results = append(results, queryResult{query: query, items: output.Items, lastEvaluatedKey: output.LastEvaluatedKey})
+	}
+
+	items := mergeResults(results, filter.Limit, filter.Descending, positions)
+
+	page := &entities.OrderPage{Orders: []entities.Order{}}
+
+	// Unmarshalling the DynamoDB items into Orders
+	var records []orderRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &records); err != nil {
+		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.tableName, err)
+		return nil, err
+	}
+	for _, record := range records {
+		order, err := record.toEntity()
+		if err != nil {
+			fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.tableName, err)
+			return nil, err
+		}
+		page.Orders = append(page.Orders, *order)
+	}
+
+	if page.NextCursor, err = encodeCursor(queries, positions); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (g *Gateway) listQueries(filter entities.OrderFilter) []listQuery {
+	var conditions []string
+	values := map[string]*dynamodb.AttributeValue{}
+
+	var filters []string
+	if filter.ItemID != "" {
+		filters = append(filters, "contains(item_ids, :item_id)")
+		values[":item_id"] = &dynamodb.AttributeValue{S: aws.String(filter.ItemID)}
+	}
+	if !filter.IncludeDeleted {
+		filters = append(filters, notDeletedFilter)
+	}
+
+	switch {
+	case !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero():
+		conditions = append(conditions, "created_at BETWEEN :created_from AND :created_to")
+	case !filter.CreatedFrom.IsZero():
+		conditions = append(conditions, "created_at >= :created_from")
+	case !filter.CreatedTo.IsZero():
+		conditions = append(conditions, "created_at <= :created_to")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		values[":created_from"] = &dynamodb.AttributeValue{S: aws.String(formatTimestamp(filter.CreatedFrom))}
+	}
+	if !filter.CreatedTo.IsZero() {
+		values[":created_to"] = &dynamodb.AttributeValue{S: aws.String(formatTimestamp(filter.CreatedTo))}
+	}
+
+	newQuery := func(name, index, hashKey string, conditions, filters []string,
+		names map[string]*string, values map[string]*dynamodb.AttributeValue) listQuery {
+		input := &dynamodb.QueryInput{
+			TableName:                 &g.tableName,
+			IndexName:                 aws.String(index),
+			KeyConditionExpression:    aws.String(strings.Join(conditions, " AND ")),
+			ExpressionAttributeValues: values,
+			ScanIndexForward:          aws.Bool(!filter.Descending),
+		}
+		if len(names) > 0 {
+			input.ExpressionAttributeNames = names
+		}
+		if len(filters) > 0 {
+			input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+		}
+		return listQuery{name: name, input: input, keyAttributes: []string{"order_id", hashKey, "created_at"}}
+	}
+
+	// Querying the orders of a client - GSI sorted by created_at, statuses are filtered
+	if filter.ClientID != "" {
+		clientValues := copyValues(values)
+		clientValues[":client_id"] = &dynamodb.AttributeValue{S: aws.String(filter.ClientID)}
+		clientNames := map[string]*string{}
+		clientFilters := filters
+		if len(filter.Statuses) > 0 {
+			clientNames["#status"] = aws.String("status")
+			var placeholders []string
+			for i, status := range filter.Statuses {
+				placeholder := ":status" + strconv.Itoa(i)
+				placeholders = append(placeholders, placeholder)
+				clientValues[placeholder] = &dynamodb.AttributeValue{S: aws.String(string(status))}
+			}
+			clientFilters = append([]string{"#status IN (" + strings.Join(placeholders, ", ") + ")"}, filters...)
+		}
+		return []listQuery{newQuery("client", clientIndex, "client_id",
+			append([]string{"client_id = :client_id"}, conditions...), clientFilters, clientNames, clientValues)}
+	}
+
+	// Querying the orders of every status - GSI sorted by created_at
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = entities.AllStatuses()
+	}
+	var queries []listQuery
+	for _, status := range statuses {
+		statusValues := copyValues(values)
+		statusValues[":status"] = &dynamodb.AttributeValue{S: aws.String(string(status))}
+		statusNames := map[string]*string{"#status": aws.String("status")}
+		queries = append(queries, newQuery(string(status), statusIndex, "status",
+			append([]string{"#status = :status"}, conditions...), filters, statusNames, statusValues))
+	}
+	return queries
+}
+
+func copyValues(values map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	copied := make(map[string]*dynamodb.AttributeValue, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// queryResult is one page of a listQuery
+type queryResult struct {
+	query            listQuery
+	items            []map[string]*dynamodb.AttributeValue
+	lastEvaluatedKey map[string]*dynamodb.AttributeValue
+}
+
+// mergeResults returns up to limit items of the query results, sorted by created_at, and moves positions
+// past the returned items. Items are only returned up to the frontier: the last item read by the queries
+// that have more pages, as their next pages may hold orders created before the items after it.
+func mergeResults(results []queryResult, limit int, descending bool,
+	positions map[string]queryPosition) []map[string]*dynamodb.AttributeValue {
+	before := func(a, b string) bool {
+		if descending {
+			return a > b
+		}
+		return a < b
+	}
+
+	frontier, bounded := "", false
+	for _, result := range results {
+		if len(result.lastEvaluatedKey) == 0 {
+			continue
+		}
+		createdAt := createdAtOf(result.lastEvaluatedKey)
+		if !bounded || before(createdAt, frontier) {
+			frontier, bounded = createdAt, true
+		}
+	}
+
+	type candidate struct {
+		result int
+		item   map[string]*dynamodb.AttributeValue
+	}
+	var candidates []candidate
+	for i, result := range results {
+		for _, item := range result.items {
+			if bounded && before(frontier, createdAtOf(item)) {
+				break
+			}
+			candidates = append(candidates, candidate{result: i, item: item})
+		}
+	}
+
+	// The stable sort keeps the index order of orders created at the same time
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return before(createdAtOf(candidates[i].item), createdAtOf(candidates[j].item))
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	taken := make([]int, len(results))
+	var items []map[string]*dynamodb.AttributeValue
+	for _, c := range candidates {
+		taken[c.result]++
+		items = append(items, c.item)
+	}
+
+	for i, result := range results {
+		switch {
+		case taken[i] == len(result.items) && len(result.lastEvaluatedKey) == 0:
+			positions[result.query.name] = queryPosition{Done: true}
+		case taken[i] == len(result.items):
+			positions[result.query.name] = queryPosition{Key: result.lastEvaluatedKey}
+		case taken[i] > 0:
+			positions[result.query.name] = queryPosition{Key: keyOf(result.items[taken[i]-1], result.query.keyAttributes)}
+		}
+	}
+
+	return items
+}
+
+func createdAtOf(item map[string]*dynamodb.AttributeValue) string {
+	if value, ok := item["created_at"]; ok {
+		return aws.StringValue(value.S)
+	}
+	return ""
+}
+
+func keyOf(item map[string]*dynamodb.AttributeValue, attributes []string) map[string]*dynamodb.AttributeValue {
+	key := map[string]*dynamodb.AttributeValue{}
+	for _, attribute := range attributes {
+		key[attribute] = item[attribute]
+	}
+	return key
+}
@@ -0,0 +1,246 @@
+package order
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"golang.org/x/exp/slices"
+)
+
+// MemoryGateway keeps the orders in memory, for local runs and tests. It behaves as the DynamoDB gateway:
+// orders are stored as records, writes are conditioned on the version read and the pending events
+// of an order are saved to the outbox along with it.
+type MemoryGateway struct {
+	mu            sync.RWMutex
+	records       map[string]orderRecord
+	outboxGateway interfaces.OutboxGatewayI
+}
+
+func NewMemoryGateway(outboxGateway interfaces.OutboxGatewayI) *MemoryGateway {
+	return &MemoryGateway{
+		records:       map[string]orderRecord{},
+		outboxGateway: outboxGateway,
+	}
+}
+
+// Save writes the order only if the stored version is still the one it was read with, bumping its version.
+// A new order (version 0) is only written if no order with the same ID exists.
+func (g *MemoryGateway) Save(order *entities.Order) (*entities.Order, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, exists := g.records[order.OrderID]
+	if (exists && stored.Version != order.Version) || (!exists && order.Version > 0) {
+		return nil, util.NewConflictError("concurrent_modification",
+			fmt.Sprintf("Order %s was modified by another request", order.OrderID))
+	}
+
+	if err := g.saveEvents(order.PendingEvents); err != nil {
+		return nil, err
+	}
+
+	order.Version++
+	g.records[order.OrderID] = newOrderRecord(order).clone()
+	order.PendingEvents = nil
+	return order, nil
+}
+
+// Update changes only the given fields of an existing order, along with updated_at and version.
+// It returns nil when the order does not exist and a conflict error when it was modified since it was read.
+func (g *MemoryGateway) Update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, exists := g.records[orderID]
+	if !exists {
+		fmt.Printf("Order ID: %s does not exist", orderID)
+		return nil, nil
+	}
+	if stored.Version != order.Version {
+		return nil, util.NewConflictError("concurrent_modification",
+			fmt.Sprintf("Order %s was modified by another request", orderID))
+	}
+
+	if err := g.saveEvents(order.PendingEvents); err != nil {
+		return nil, err
+	}
+
+	changed := newOrderRecord(order).clone()
+	for _, field := range fields {
+		switch field {
+		case entities.OrderStatusField:
+			stored.Status = changed.Status
+			stored.StatusHistory = changed.StatusHistory
+		case entities.OrderNotesField:
+			stored.Notes = changed.Notes
+		case entities.OrderItemsField:
+			stored.OrderedItems = changed.OrderedItems
+			stored.ItemIDs = changed.ItemIDs
+			stored.Subtotal = changed.Subtotal
+			stored.Discount = changed.Discount
+			stored.Total = changed.Total
+		case entities.OrderDeletionField:
+			stored.DeletedAt = changed.DeletedAt
+			stored.DeletedBy = changed.DeletedBy
+		case entities.OrderClaimField:
+			stored.Station = changed.Station
+			stored.ClaimedAt = changed.ClaimedAt
+		}
+	}
+	stored.UpdatedAt = changed.UpdatedAt
+	stored.Version++
+
+	g.records[orderID] = stored
+	order.PendingEvents = nil
+	updated := stored.clone()
+	return updated.toEntity()
+}
+
+// saveEvents writes the pending events of an order to the outbox
+func (g *MemoryGateway) saveEvents(events []entities.OutboxEvent) error {
+	for i := range events {
+		event := events[i]
+		if err := g.outboxGateway.Save(&event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByID returns the order even when it was soft deleted
+func (g *MemoryGateway) GetByID(orderID string) (*entities.Order, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	stored, exists := g.records[orderID]
+	if !exists {
+		fmt.Printf("Order ID: %s does not exist", orderID)
+		return nil, nil
+	}
+
+	fetched := stored.clone()
+	return fetched.toEntity()
+}
+
+// GetAll pages through the orders matching the filter, sorted by created_at and then by order ID
+func (g *MemoryGateway) GetAll(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	lastOrderID, lastCreatedAt, err := decodeKeysetCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.RLock()
+	var records []orderRecord
+	for _, record := range g.records {
+		if matches(record, filter) {
+			records = append(records, record.clone())
+		}
+	}
+	g.mu.RUnlock()
+
+	before := func(a, b orderRecord) bool {
+		if filter.Descending {
+			a, b = b, a
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.OrderID < b.OrderID
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return before(records[i], records[j])
+	})
+
+	if lastOrderID != "" {
+		last := orderRecord{OrderID: lastOrderID, CreatedAt: lastCreatedAt}
+		start := sort.Search(len(records), func(i int) bool {
+			return before(last, records[i])
+		})
+		records = records[start:]
+	}
+
+	page := &entities.OrderPage{Orders: []entities.Order{}}
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+		last := records[len(records)-1]
+		if page.NextCursor, err = encodeKeysetCursor(last.OrderID, last.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, record := range records {
+		order, err := record.toEntity()
+		if err != nil {
+			return nil, err
+		}
+		page.Orders = append(page.Orders, *order)
+	}
+
+	return page, nil
+}
+
+// matches applies the filter to a stored order, as the queries and filter expressions of GetAll do
+func matches(record orderRecord, filter entities.OrderFilter) bool {
+	switch {
+	case filter.ClientID != "" && record.ClientID != filter.ClientID:
+		return false
+	case len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, record.Status):
+		return false
+	case !filter.CreatedFrom.IsZero() && record.CreatedAt < formatTimestamp(filter.CreatedFrom):
+		return false
+	case !filter.CreatedTo.IsZero() && record.CreatedAt > formatTimestamp(filter.CreatedTo):
+		return false
+	case filter.ItemID != "" && !slices.Contains(record.ItemIDs, filter.ItemID):
+		return false
+	case !filter.IncludeDeleted && record.DeletedAt != "":
+		return false
+	}
+	return true
+}
+
+// Import stores items of the DynamoDB orders table as they are, without migrating them,
+// so orders written by older versions of the API can be read and migrated in memory
+func (g *MemoryGateway) Import(items []map[string]*dynamodb.AttributeValue) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, item := range items {
+		var record orderRecord
+		if err := dynamodbattribute.UnmarshalMap(item, &record); err != nil {
+			return err
+		}
+		g.records[record.OrderID] = record
+	}
+	return nil
+}
+
+// Migrate rewrites the orders written by older versions of the API, as Gateway.Migrate does on the DynamoDB table
+func (g *MemoryGateway) Migrate() (MigrationResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var result MigrationResult
+	for orderID, record := range g.records {
+		result.Scanned++
+		if !record.needsMigration() {
+			continue
+		}
+
+		migrated, err := record.migrated()
+		if err != nil {
+			fmt.Printf("Error migrating order ID: %s\nerror: %s\n", orderID, err)
+			result.Failed++
+			continue
+		}
+		g.records[orderID] = *migrated
+		result.Migrated++
+	}
+
+	return result, nil
+}
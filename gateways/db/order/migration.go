@@ -0,0 +1,125 @@
+package order
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// MigrationResult counts the orders seen by a migration
+type MigrationResult struct {
+	Scanned  int
+	Migrated int
+	Failed   int
+}
+
+// Migrate rewrites the orders written by older versions of the API: timestamps stored as time.Time.String()
+// are rewritten in timestampLayout and item_ids is added to the orders without it.
+// Orders are rewritten only if they were not modified since they were read, and keep their version,
+// so running the migration again only retries the orders it could not migrate.
+func (g *Gateway) Migrate() (MigrationResult, error) {
+	var result MigrationResult
+	var migrationErr error
+
+	err := g.repository.ScanPages(&dynamodb.ScanInput{TableName: &g.tableName},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			for _, item := range page.Items {
+				result.Scanned++
+
+				var record orderRecord
+				if err := dynamodbattribute.UnmarshalMap(item, &record); err != nil {
+					migrationErr = err
+					return false
+				}
+
+				if !record.needsMigration() {
+					continue
+				}
+
+				if err := g.migrateRecord(&record); err != nil {
+					fmt.Printf("Error migrating order ID: %s\nerror: %s\n", record.OrderID, err)
+					result.Failed++
+					continue
+				}
+				result.Migrated++
+			}
+			return true
+		})
+	if err != nil {
+		fmt.Printf("Error scanning table %s - Error: %s", g.tableName, err)
+		return result, err
+	}
+
+	return result, migrationErr
+}
+
+func (r *orderRecord) needsMigration() bool {
+	if len(r.ItemIDs) == 0 && len(r.OrderedItems) > 0 {
+		return true
+	}
+	if isLegacyTimestamp(r.CreatedAt) || isLegacyTimestamp(r.UpdatedAt) || isLegacyTimestamp(r.DeletedAt) {
+		return true
+	}
+	for _, change := range r.StatusHistory {
+		if isLegacyTimestamp(change.ChangedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// migrated returns the record as the current version of the API writes it
+func (r *orderRecord) migrated() (*orderRecord, error) {
+	order, err := r.toEntity()
+	if err != nil {
+		return nil, err
+	}
+	return newOrderRecord(order), nil
+}
+
+func (g *Gateway) migrateRecord(record *orderRecord) error {
+	migrated, err := record.migrated()
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		":created_at":     migrated.CreatedAt,
+		":updated_at":     migrated.UpdatedAt,
+		":status_history": migrated.StatusHistory,
+		":item_ids":       migrated.ItemIDs,
+	}
+	sets := "SET created_at = :created_at, updated_at = :updated_at, status_history = :status_history, item_ids = :item_ids"
+	if migrated.DeletedAt != "" {
+		values[":deleted_at"] = migrated.DeletedAt
+		sets += ", deleted_at = :deleted_at"
+	}
+
+	condition := "attribute_not_exists(#version)"
+	if record.Version > 0 {
+		condition = "#version = :version"
+		values[":version"] = record.Version
+	}
+
+	attributeValues, err := dynamodbattribute.MarshalMap(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.repository.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: &g.tableName,
+		Key: map[string]*dynamodb.AttributeValue{
+			"order_id": {S: aws.String(record.OrderID)},
+		},
+		UpdateExpression:          aws.String(sets),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  map[string]*string{"#version": aws.String("version")},
+		ExpressionAttributeValues: attributeValues,
+	})
+	if failed, _ := conditionFailure(err); failed {
+		return fmt.Errorf("order was modified since version %d was read, run the migration again", record.Version)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+CREATE TABLE orders (
+    order_id       TEXT PRIMARY KEY,
+    client_id      TEXT        NOT NULL,
+    status         TEXT        NOT NULL,
+    notes          TEXT        NOT NULL DEFAULT '',
+    subtotal_cents BIGINT      NOT NULL DEFAULT 0,
+    discount_cents BIGINT      NOT NULL DEFAULT 0,
+    total_cents    BIGINT      NOT NULL DEFAULT 0,
+    status_history JSONB       NOT NULL DEFAULT '[]',
+    created_at     TIMESTAMPTZ NOT NULL,
+    updated_at     TIMESTAMPTZ NOT NULL,
+    version        INTEGER     NOT NULL,
+    deleted_at     TIMESTAMPTZ,
+    deleted_by     TEXT        NOT NULL DEFAULT '',
+    station        TEXT        NOT NULL DEFAULT '',
+    claimed_at     TIMESTAMPTZ
+);
+
+-- Listings are sorted by created_at, then by order_id, within a client or a status
+CREATE INDEX orders_client_id_created_at_idx ON orders (client_id, created_at, order_id);
+CREATE INDEX orders_status_created_at_idx ON orders (status, created_at, order_id);
+CREATE INDEX orders_created_at_idx ON orders (created_at, order_id);
+
+CREATE TABLE ordered_items (
+    order_id         TEXT             NOT NULL REFERENCES orders (order_id) ON DELETE CASCADE,
+    position         INTEGER          NOT NULL,
+    item_id          TEXT             NOT NULL,
+    name             TEXT             NOT NULL DEFAULT '',
+    category         TEXT             NOT NULL DEFAULT '',
+    description      TEXT             NOT NULL DEFAULT '',
+    price            DOUBLE PRECISION NOT NULL DEFAULT 0,
+    quantity         INTEGER          NOT NULL,
+    line_total_cents BIGINT           NOT NULL DEFAULT 0,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE INDEX ordered_items_item_id_idx ON ordered_items (item_id, order_id);
+
+CREATE TABLE orders_outbox (
+    event_id        TEXT PRIMARY KEY,
+    order_id        TEXT        NOT NULL,
+    event           JSONB       NOT NULL,
+    status          TEXT        NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    sent_at         TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX orders_outbox_status_created_at_idx ON orders_outbox (status, created_at);
@@ -0,0 +1,4 @@
+-- The relay queries the pending events that are due, by next_attempt_at
+CREATE INDEX orders_outbox_status_next_attempt_at_idx ON orders_outbox (status, next_attempt_at);
+
+DROP INDEX orders_outbox_status_created_at_idx;
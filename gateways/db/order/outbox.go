@@ -0,0 +1,83 @@
+package order
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// outboxRecord is an outbox event as it is stored in DynamoDB. Its timestamps are in timestampLayout,
+// so the outbox StatusNextAttemptAtIndex can be queried for the events that are due.
+type outboxRecord struct {
+	EventID       string                `json:"event_id"`
+	OrderID       string                `json:"order_id"`
+	Event         entities.OrderEvent   `json:"event"`
+	Status        entities.OutboxStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	LastError     string                `json:"last_error"`
+	CreatedAt     string                `json:"created_at"`
+	NextAttemptAt string                `json:"next_attempt_at"`
+	SentAt        string                `json:"sent_at,omitempty"`
+}
+
+// MarshalOutboxEvent returns the DynamoDB item of an outbox event. It is shared with the outbox gateway,
+// which updates the events this gateway writes along with the orders.
+func MarshalOutboxEvent(event *entities.OutboxEvent) (map[string]*dynamodb.AttributeValue, error) {
+	record := outboxRecord{
+		EventID:       event.EventID,
+		OrderID:       event.OrderID,
+		Event:         event.Event,
+		Status:        event.Status,
+		Attempts:      event.Attempts,
+		LastError:     event.LastError,
+		CreatedAt:     formatTimestamp(event.CreatedAt),
+		NextAttemptAt: formatTimestamp(event.NextAttemptAt),
+		SentAt:        formatTimestamp(event.SentAt),
+	}
+	// next_attempt_at is an index key, which cannot be empty. An event without it is due right away.
+	if record.NextAttemptAt == "" {
+		record.NextAttemptAt = record.CreatedAt
+	}
+
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return nil, err
+	}
+	return item, nil
+}
+
+// UnmarshalOutboxEvents reads the DynamoDB items of outbox events, including the ones written with
+// RFC 3339 timestamps before they were stored in timestampLayout
+func UnmarshalOutboxEvents(items []map[string]*dynamodb.AttributeValue) ([]entities.OutboxEvent, error) {
+	var records []outboxRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &records); err != nil {
+		return nil, err
+	}
+
+	events := make([]entities.OutboxEvent, 0, len(records))
+	for _, record := range records {
+		event := entities.OutboxEvent{
+			EventID:   record.EventID,
+			OrderID:   record.OrderID,
+			Event:     record.Event,
+			Status:    record.Status,
+			Attempts:  record.Attempts,
+			LastError: record.LastError,
+		}
+		var err error
+		if event.CreatedAt, err = parseTimestamp(record.CreatedAt); err != nil {
+			return nil, err
+		}
+		if event.NextAttemptAt, err = parseTimestamp(record.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		if event.SentAt, err = parseTimestamp(record.SentAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
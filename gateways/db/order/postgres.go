@@ -0,0 +1,445 @@
+package order
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+// orderColumns are the columns of the orders table, in the order orderArgs and scanOrder use
+const orderColumns = "order_id, client_id, status, notes, subtotal_cents, discount_cents, total_cents, status_history, " +
+	"created_at, updated_at, version, deleted_at, deleted_by, station, claimed_at"
+
+// PostgresGateway stores the orders in Postgres: the orders table holds the order and its status history,
+// ordered_items holds its items. The pending events of an order are written to orders_outbox in the same transaction.
+// The tables are created by MigratePostgres. Postgres keeps timestamps to the microsecond.
+type PostgresGateway struct {
+	db *sql.DB
+}
+
+func NewPostgresGateway(db *sql.DB) *PostgresGateway {
+	return &PostgresGateway{db: db}
+}
+
+// querier is a *sql.DB or a *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Save writes the order only if the stored version is still the one it was read with, bumping its version.
+// A new order (version 0) is only written if no order with the same ID exists.
+func (g *PostgresGateway) Save(order *entities.Order) (*entities.Order, error) {
+	expectedVersion := order.Version
+
+	args, err := orderArgs(order, expectedVersion+1)
+	if err != nil {
+		fmt.Println("Error marshaling order:", err)
+		return nil, err
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		fmt.Println("Error starting transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var result sql.Result
+	if expectedVersion == 0 {
+		result, err = tx.Exec("INSERT INTO orders ("+orderColumns+") VALUES "+
+			"($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) ON CONFLICT (order_id) DO NOTHING", args...)
+	} else {
+		result, err = tx.Exec(`UPDATE orders SET client_id = $2, status = $3, notes = $4, subtotal_cents = $5,
+			discount_cents = $6, total_cents = $7, status_history = $8, created_at = $9, updated_at = $10, version = $11,
+			deleted_at = $12, deleted_by = $13, station = $14, claimed_at = $15
+			WHERE order_id = $1 AND version = $16`, append(args, expectedVersion)...)
+	}
+	if err != nil {
+		fmt.Println("Error inserting order:", err)
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, util.NewConflictError("concurrent_modification",
+			fmt.Sprintf("Order %s was modified by another request", order.OrderID))
+	}
+
+	if err := replaceOrderedItems(tx, order.OrderID, order.OrderedItems); err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvents(tx, order.PendingEvents); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Println("Error committing order:", err)
+		return nil, err
+	}
+
+	order.Version = expectedVersion + 1
+	order.PendingEvents = nil
+	fmt.Println("Item inserted successfully")
+	return order, nil
+}
+
+// Update changes only the given fields of an existing order, along with updated_at and version.
+// It returns nil when the order does not exist and a conflict error when it was modified since it was read.
+func (g *PostgresGateway) Update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error) {
+	args := []interface{}{orderID, order.Version, order.UpdatedAt.UTC()}
+	sets := []string{"updated_at = $3", "version = version + 1"}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	replaceItems := false
+	for _, field := range fields {
+		switch field {
+		case entities.OrderStatusField:
+			history, err := json.Marshal(newStatusHistoryRecord(order.StatusHistory))
+			if err != nil {
+				return nil, err
+			}
+			set("status", order.Status)
+			set("status_history", string(history))
+		case entities.OrderNotesField:
+			set("notes", order.Notes)
+		case entities.OrderItemsField:
+			replaceItems = true
+			set("subtotal_cents", order.Subtotal)
+			set("discount_cents", order.Discount)
+			set("total_cents", order.Total)
+		case entities.OrderDeletionField:
+			set("deleted_at", nullTime(order.DeletedAt))
+			set("deleted_by", order.DeletedBy)
+		case entities.OrderClaimField:
+			set("station", order.Station)
+			set("claimed_at", nullTime(order.ClaimedAt))
+		}
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		fmt.Println("Error starting transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE orders SET "+strings.Join(sets, ", ")+" WHERE order_id = $1 AND version = $2", args...)
+	if err != nil {
+		fmt.Printf("Error updating order ID: %s\nerror: %s", orderID, err)
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM orders WHERE order_id = $1)", orderID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			fmt.Printf("Order ID: %s does not exist", orderID)
+			return nil, nil
+		}
+		return nil, util.NewConflictError("concurrent_modification",
+			fmt.Sprintf("Order %s was modified by another request", orderID))
+	}
+
+	if replaceItems {
+		if err := replaceOrderedItems(tx, orderID, order.OrderedItems); err != nil {
+			return nil, err
+		}
+	}
+	if err := insertOutboxEvents(tx, order.PendingEvents); err != nil {
+		return nil, err
+	}
+
+	orderUpdated, err := getOrder(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Println("Error committing order:", err)
+		return nil, err
+	}
+
+	order.PendingEvents = nil
+	return orderUpdated, nil
+}
+
+// GetByID returns the order even when it was soft deleted
+func (g *PostgresGateway) GetByID(orderID string) (*entities.Order, error) {
+	order, err := getOrder(g.db, orderID)
+	if err == nil && order == nil {
+		fmt.Printf("Order ID: %s does not exist", orderID)
+	}
+	return order, err
+}
+
+// GetAll pages through the orders matching the filter, sorted by created_at and then by order ID.
+// The client and status filters use the (client_id, created_at) and (status, created_at) indexes.
+func (g *PostgresGateway) GetAll(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	lastOrderID, lastCreatedAt, err := decodeKeysetCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.ClientID != "" {
+		where("client_id = %s", filter.ClientID)
+	}
+	if len(filter.Statuses) > 0 {
+		var statuses []string
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		where("status = ANY(%s)", pq.Array(statuses))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("created_at >= %s", filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("created_at <= %s", filter.CreatedTo.UTC())
+	}
+	if filter.ItemID != "" {
+		where("EXISTS (SELECT 1 FROM ordered_items i WHERE i.order_id = orders.order_id AND i.item_id = %s)", filter.ItemID)
+	}
+	if !filter.IncludeDeleted {
+		where("deleted_at IS NULL")
+	}
+
+	direction, after := "ASC", ">"
+	if filter.Descending {
+		direction, after = "DESC", "<"
+	}
+	if lastOrderID != "" {
+		createdAt, err := parseTimestamp(lastCreatedAt)
+		if err != nil {
+			return nil, util.NewBadRequestError("invalid_cursor", "Invalid cursor")
+		}
+		where("(created_at, order_id) "+after+" (%s::timestamptz, %s::text)", createdAt, lastOrderID)
+	}
+
+	query := "SELECT " + orderColumns + " FROM orders"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY created_at %s, order_id %s", direction, direction)
+	if filter.Limit > 0 {
+		// One more order tells whether there is a next page
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit+1)
+	}
+
+	orders, err := queryOrders(g.db, query, args...)
+	if err != nil {
+		fmt.Printf("Error querying orders - Error: %s", err)
+		return nil, err
+	}
+
+	page := &entities.OrderPage{Orders: []entities.Order{}}
+	if filter.Limit > 0 && len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		last := orders[len(orders)-1]
+		if page.NextCursor, err = encodeKeysetCursor(last.OrderID, formatTimestamp(last.CreatedAt)); err != nil {
+			return nil, err
+		}
+	}
+	for _, order := range orders {
+		page.Orders = append(page.Orders, *order)
+	}
+
+	return page, nil
+}
+
+func getOrder(q querier, orderID string) (*entities.Order, error) {
+	orders, err := queryOrders(q, "SELECT "+orderColumns+" FROM orders WHERE order_id = $1", orderID)
+	if err != nil {
+		fmt.Printf("Error fetching order ID: %s\nerror: %s", orderID, err)
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	return orders[0], nil
+}
+
+// queryOrders runs a query selecting orderColumns and loads the ordered items of the orders found
+func queryOrders(q querier, query string, args ...interface{}) ([]*entities.Order, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*entities.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+	return orders, loadOrderedItems(q, orders)
+}
+
+func scanOrder(rows *sql.Rows) (*entities.Order, error) {
+	var order entities.Order
+	var history []byte
+	var deletedAt, claimedAt sql.NullTime
+	err := rows.Scan(&order.OrderID, &order.ClientID, &order.Status, &order.Notes, &order.Subtotal, &order.Discount,
+		&order.Total, &history, &order.CreatedAt, &order.UpdatedAt, &order.Version, &deletedAt, &order.DeletedBy,
+		&order.Station, &claimedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	order.CreatedAt = order.CreatedAt.UTC()
+	order.UpdatedAt = order.UpdatedAt.UTC()
+	order.DeletedAt = timeFromNull(deletedAt)
+	order.ClaimedAt = timeFromNull(claimedAt)
+
+	var records []statusChangeRecord
+	if err := json.Unmarshal(history, &records); err != nil {
+		return nil, err
+	}
+	for _, change := range records {
+		changedAt, err := parseTimestamp(change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		order.StatusHistory = append(order.StatusHistory, entities.StatusChange{
+			From:      change.From,
+			To:        change.To,
+			ChangedAt: changedAt,
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		})
+	}
+
+	return &order, nil
+}
+
+func loadOrderedItems(q querier, orders []*entities.Order) error {
+	byID := map[string]*entities.Order{}
+	var ids []string
+	for _, order := range orders {
+		byID[order.OrderID] = order
+		ids = append(ids, order.OrderID)
+	}
+
+	rows, err := q.Query(`SELECT order_id, item_id, name, category, description, price, quantity, line_total_cents
+		FROM ordered_items WHERE order_id = ANY($1) ORDER BY order_id, position`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var item entities.OrderedItem
+		if err := rows.Scan(&orderID, &item.ItemID, &item.Name, &item.Category, &item.Description, &item.Price,
+			&item.Quantity, &item.LineTotal); err != nil {
+			return err
+		}
+		order := byID[orderID]
+		order.OrderedItems = append(order.OrderedItems, item)
+	}
+
+	return rows.Err()
+}
+
+func replaceOrderedItems(tx *sql.Tx, orderID string, items []entities.OrderedItem) error {
+	if _, err := tx.Exec("DELETE FROM ordered_items WHERE order_id = $1", orderID); err != nil {
+		fmt.Printf("Error deleting items of order ID: %s\nerror: %s", orderID, err)
+		return err
+	}
+
+	for position, item := range items {
+		_, err := tx.Exec(`INSERT INTO ordered_items
+			(order_id, position, item_id, name, category, description, price, quantity, line_total_cents)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			orderID, position, item.ItemID, item.Name, item.Category, item.Description, item.Price, item.Quantity,
+			item.LineTotal)
+		if err != nil {
+			fmt.Printf("Error inserting items of order ID: %s\nerror: %s", orderID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertOutboxEvents(tx *sql.Tx, events []entities.OutboxEvent) error {
+	for _, event := range events {
+		data, err := json.Marshal(event.Event)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT INTO orders_outbox
+			(event_id, order_id, event, status, attempts, last_error, created_at, next_attempt_at, sent_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			event.EventID, event.OrderID, string(data), event.Status, event.Attempts, event.LastError,
+			event.CreatedAt.UTC(), event.NextAttemptAt.UTC(), event.SentAt.UTC())
+		if err != nil {
+			fmt.Printf("Error saving outbox event %s: %s\n", event.EventID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// orderArgs returns the values of orderColumns for the order, stored with the given version
+func orderArgs(order *entities.Order, version int) ([]interface{}, error) {
+	history, err := json.Marshal(newStatusHistoryRecord(order.StatusHistory))
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{order.OrderID, order.ClientID, order.Status, order.Notes, order.Subtotal, order.Discount,
+		order.Total, string(history), order.CreatedAt.UTC(), order.UpdatedAt.UTC(), version, nullTime(order.DeletedAt),
+		order.DeletedBy, order.Station, nullTime(order.ClaimedAt)}, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timeFromNull(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
@@ -0,0 +1,212 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// timestampLayout is RFC 3339 in UTC with a fixed number of decimals,
+// so stored timestamps sort as strings the same way they sort as times (StatusCreatedAtIndex relies on it)
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// legacyTimestampLayout is how time.Time.String() wrote timestamps before they were stored as timestampLayout
+const legacyTimestampLayout = "2006-01-02 15:04:05.999999999 -0700"
+
+// orderRecord is an order as it is stored in DynamoDB
+type orderRecord struct {
+	OrderID       string                 `json:"order_id"`
+	ClientID      string                 `json:"client_id"`
+	Status        entities.Status        `json:"status"`
+	OrderedItems  []entities.OrderedItem `json:"ordered_items"`
+	Notes         string                 `json:"notes"`
+	Subtotal      entities.Cents         `json:"subtotal_cents"`
+	Discount      entities.Cents         `json:"discount_cents"`
+	Total         entities.Cents         `json:"total_cents"`
+	CreatedAt     string                 `json:"created_at"`
+	UpdatedAt     string                 `json:"updated_at"`
+	StatusHistory []statusChangeRecord   `json:"status_history"`
+	Version       int                    `json:"version"`
+	DeletedAt     string                 `json:"deleted_at,omitempty"`
+	DeletedBy     string                 `json:"deleted_by,omitempty"`
+	Station       string                 `json:"station,omitempty"`
+	ClaimedAt     string                 `json:"claimed_at,omitempty"`
+	// ItemIDs repeats the ordered item IDs, so listings can filter on them with contains()
+	ItemIDs []string `json:"item_ids,omitempty"`
+}
+
+type statusChangeRecord struct {
+	From      entities.Status `json:"from"`
+	To        entities.Status `json:"to"`
+	ChangedAt string          `json:"changed_at"`
+	Actor     string          `json:"actor"`
+	Reason    string          `json:"reason"`
+}
+
+func newOrderRecord(order *entities.Order) *orderRecord {
+	record := &orderRecord{
+		OrderID:       order.OrderID,
+		ClientID:      order.ClientID,
+		Status:        order.Status,
+		OrderedItems:  order.OrderedItems,
+		ItemIDs:       orderedItemIDs(order.OrderedItems),
+		Notes:         order.Notes,
+		Subtotal:      order.Subtotal,
+		Discount:      order.Discount,
+		Total:         order.Total,
+		CreatedAt:     formatTimestamp(order.CreatedAt),
+		UpdatedAt:     formatTimestamp(order.UpdatedAt),
+		StatusHistory: newStatusHistoryRecord(order.StatusHistory),
+		Version:       order.Version,
+		DeletedBy:     order.DeletedBy,
+		Station:       order.Station,
+	}
+	if order.DeletedAt != nil {
+		record.DeletedAt = formatTimestamp(*order.DeletedAt)
+	}
+	if order.ClaimedAt != nil {
+		record.ClaimedAt = formatTimestamp(*order.ClaimedAt)
+	}
+	return record
+}
+
+// orderedItemIDs returns the distinct IDs of the ordered items
+func orderedItemIDs(items []entities.OrderedItem) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, item := range items {
+		if !seen[item.ItemID] {
+			seen[item.ItemID] = true
+			ids = append(ids, item.ItemID)
+		}
+	}
+	return ids
+}
+
+func newStatusHistoryRecord(history []entities.StatusChange) []statusChangeRecord {
+	var records []statusChangeRecord
+	for _, change := range history {
+		records = append(records, statusChangeRecord{
+			From:      change.From,
+			To:        change.To,
+			ChangedAt: formatTimestamp(change.ChangedAt),
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		})
+	}
+	return records
+}
+
+// clone copies the record along with its slices, so the copy shares nothing with the original
+func (r *orderRecord) clone() orderRecord {
+	cloned := *r
+	cloned.OrderedItems = append([]entities.OrderedItem(nil), r.OrderedItems...)
+	cloned.StatusHistory = append([]statusChangeRecord(nil), r.StatusHistory...)
+	cloned.ItemIDs = append([]string(nil), r.ItemIDs...)
+	return cloned
+}
+
+func (r *orderRecord) toEntity() (*entities.Order, error) {
+	order := &entities.Order{
+		OrderID:      r.OrderID,
+		ClientID:     r.ClientID,
+		Status:       r.Status,
+		OrderedItems: r.OrderedItems,
+		Notes:        r.Notes,
+		Subtotal:     r.Subtotal,
+		Discount:     r.Discount,
+		Total:        r.Total,
+		Version:      r.Version,
+		DeletedBy:    r.DeletedBy,
+		Station:      r.Station,
+	}
+
+	var err error
+	if order.CreatedAt, err = parseTimestamp(r.CreatedAt); err != nil {
+		return nil, err
+	}
+	if order.UpdatedAt, err = parseTimestamp(r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if r.DeletedAt != "" {
+		deletedAt, err := parseTimestamp(r.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		order.DeletedAt = &deletedAt
+	}
+	if r.ClaimedAt != "" {
+		claimedAt, err := parseTimestamp(r.ClaimedAt)
+		if err != nil {
+			return nil, err
+		}
+		order.ClaimedAt = &claimedAt
+	}
+
+	for _, change := range r.StatusHistory {
+		changedAt, err := parseTimestamp(change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		order.StatusHistory = append(order.StatusHistory, entities.StatusChange{
+			From:      change.From,
+			To:        change.To,
+			ChangedAt: changedAt,
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		})
+	}
+
+	return order, nil
+}
+
+// FormatTimestamp returns t as the items of the API store it, in timestampLayout, for the gateways of the other
+// tables and for queries on stored timestamps. The zero time is stored as an empty string.
+func FormatTimestamp(t time.Time) string {
+	return formatTimestamp(t)
+}
+
+// ParseTimestamp reads a stored timestamp, in timestampLayout or in the forms written before it
+func ParseTimestamp(value string) (time.Time, error) {
+	return parseTimestamp(value)
+}
+
+// formatTimestamp returns the stored form of t. The zero time is stored as an empty string.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+// parseTimestamp reads a stored timestamp, in timestampLayout or in the legacy time.Time.String() form
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	// time.Time.String() is "<date> <time> <offset> <zone name>", followed by the monotonic clock reading when present
+	parts := strings.Fields(value)
+	if len(parts) >= 3 {
+		if t, err := time.Parse(legacyTimestampLayout, strings.Join(parts[:3], " ")); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// isLegacyTimestamp reports whether a stored timestamp still has to be rewritten in timestampLayout
+func isLegacyTimestamp(value string) bool {
+	if value == "" {
+		return false
+	}
+	_, err := time.Parse(timestampLayout, value)
+	return err != nil
+}
@@ -0,0 +1,84 @@
+package order
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// migrations are the Postgres schema changes, applied in file name order
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLock is the Postgres advisory lock key serializing the migrations of instances starting together
+const migrationLock = 16_2023
+
+// MigratePostgres applies the embedded migrations that were not applied yet, each one in its own transaction,
+// and records them in schema_migrations. It is safe to run more than once.
+func MigratePostgres(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		fmt.Println("Error creating schema_migrations:", err)
+		return err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := applyMigration(db, name); err != nil {
+			fmt.Printf("Error applying migration %s: %s\n", name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, name string) error {
+	version := strings.TrimSuffix(path.Base(name), ".sql")
+	script, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLock); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version).
+		Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.Exec(string(script)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return err
+	}
+
+	fmt.Printf("Migration %s applied\n", version)
+	return tx.Commit()
+}
@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+)
+
+type Gateway struct {
+	tableName  string
+	repository *dynamodb.DynamoDB
+}
+
+// NewGateway returns the gateway of the outbox table of the orders
+func NewGateway(repository *dynamodb.DynamoDB, tableName string) *Gateway {
+	return &Gateway{
+		tableName:  tableName,
+		repository: repository,
+	}
+}
+
+// GetPending returns the pending events due at now, the longest due first, using the StatusNextAttemptAtIndex
+func (g *Gateway) GetPending(now time.Time, limit int) ([]entities.OutboxEvent, error) {
+	query := &dynamodb.QueryInput{
+		TableName:                &g.tableName,
+		IndexName:                aws.String("StatusNextAttemptAtIndex"),
+		KeyConditionExpression:   aws.String("#status = :status AND next_attempt_at <= :now"),
+		ExpressionAttributeNames: map[string]*string{"#status": aws.String("status")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status": {S: aws.String(string(entities.PendingOutboxStatus))},
+			":now":    {S: aws.String(og.FormatTimestamp(now))},
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int64(int64(limit)),
+	}
+
+	result, err := g.repository.Query(query)
+	if err != nil {
+		fmt.Printf("Error querying table %s - Error: %s", g.tableName, err)
+		return nil, err
+	}
+
+	events, err := og.UnmarshalOutboxEvents(result.Items)
+	if err != nil {
+		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.tableName, err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (g *Gateway) Save(event *entities.OutboxEvent) error {
+	item, err := og.MarshalOutboxEvent(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName: &g.tableName,
+		Item:      item,
+	})
+	if err != nil {
+		fmt.Printf("Error saving outbox event %s: %s\n", event.EventID, err)
+		return err
+	}
+
+	return nil
+}
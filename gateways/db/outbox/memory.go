@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// MemoryGateway keeps the outbox events in memory, for local runs and tests
+type MemoryGateway struct {
+	mu     sync.RWMutex
+	events map[string]entities.OutboxEvent
+}
+
+func NewMemoryGateway() *MemoryGateway {
+	return &MemoryGateway{events: map[string]entities.OutboxEvent{}}
+}
+
+// GetPending returns the pending events due at now, the longest due first
+func (g *MemoryGateway) GetPending(now time.Time, limit int) ([]entities.OutboxEvent, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var events []entities.OutboxEvent
+	for _, event := range g.events {
+		if event.Status == entities.PendingOutboxStatus && !event.NextAttemptAt.After(now) {
+			events = append(events, event)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].NextAttemptAt.Equal(events[j].NextAttemptAt) {
+			return events[i].NextAttemptAt.Before(events[j].NextAttemptAt)
+		}
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+func (g *MemoryGateway) Save(event *entities.OutboxEvent) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.events[event.EventID] = *event
+	return nil
+}
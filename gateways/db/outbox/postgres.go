@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// PostgresGateway reads and updates the orders_outbox table, written by the Postgres order gateway
+type PostgresGateway struct {
+	db *sql.DB
+}
+
+func NewPostgresGateway(db *sql.DB) *PostgresGateway {
+	return &PostgresGateway{db: db}
+}
+
+// GetPending returns the pending events due at now, the longest due first
+func (g *PostgresGateway) GetPending(now time.Time, limit int) ([]entities.OutboxEvent, error) {
+	rows, err := g.db.Query(`SELECT event_id, order_id, event, status, attempts, last_error, created_at, next_attempt_at, sent_at
+		FROM orders_outbox WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at, created_at LIMIT $3`,
+		entities.PendingOutboxStatus, now.UTC(), limit)
+	if err != nil {
+		fmt.Printf("Error querying table orders_outbox - Error: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entities.OutboxEvent
+	for rows.Next() {
+		var event entities.OutboxEvent
+		var data []byte
+		if err := rows.Scan(&event.EventID, &event.OrderID, &data, &event.Status, &event.Attempts, &event.LastError,
+			&event.CreatedAt, &event.NextAttemptAt, &event.SentAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &event.Event); err != nil {
+			return nil, err
+		}
+		event.CreatedAt = event.CreatedAt.UTC()
+		event.NextAttemptAt = event.NextAttemptAt.UTC()
+		event.SentAt = event.SentAt.UTC()
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (g *PostgresGateway) Save(event *entities.OutboxEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.Exec(`INSERT INTO orders_outbox
+		(event_id, order_id, event, status, attempts, last_error, created_at, next_attempt_at, sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (event_id) DO UPDATE SET event = EXCLUDED.event, status = EXCLUDED.status,
+		attempts = EXCLUDED.attempts, last_error = EXCLUDED.last_error, next_attempt_at = EXCLUDED.next_attempt_at,
+		sent_at = EXCLUDED.sent_at`,
+		event.EventID, event.OrderID, string(data), event.Status, event.Attempts, event.LastError,
+		event.CreatedAt.UTC(), event.NextAttemptAt.UTC(), event.SentAt.UTC())
+	if err != nil {
+		fmt.Printf("Error saving outbox event %s: %s\n", event.EventID, err)
+		return err
+	}
+
+	return nil
+}
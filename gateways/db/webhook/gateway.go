@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+)
+
+type Gateway struct {
+	subscriptionsTableName string
+	deliveriesTableName    string
+	repository             *dynamodb.DynamoDB
+}
+
+// NewGateway returns the gateway of the webhook subscriptions and deliveries tables
+func NewGateway(repository *dynamodb.DynamoDB, subscriptionsTableName, deliveriesTableName string) *Gateway {
+	return &Gateway{
+		subscriptionsTableName: subscriptionsTableName,
+		deliveriesTableName:    deliveriesTableName,
+		repository:             repository,
+	}
+}
+
+func (g *Gateway) SaveSubscription(subscription *entities.WebhookSubscription) error {
+	return g.put(g.subscriptionsTableName, subscription.SubscriptionID, subscription)
+}
+
+func (g *Gateway) GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error) {
+	var subscription entities.WebhookSubscription
+	found, err := g.get(g.subscriptionsTableName, "subscription_id", subscriptionID, &subscription)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (g *Gateway) GetAllSubscriptions() ([]entities.WebhookSubscription, error) {
+	subscriptions := []entities.WebhookSubscription{}
+	var unmarshalErr error
+	err := g.repository.ScanPages(&dynamodb.ScanInput{TableName: &g.subscriptionsTableName},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var pageSubscriptions []entities.WebhookSubscription
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageSubscriptions); unmarshalErr != nil {
+				return false
+			}
+			subscriptions = append(subscriptions, pageSubscriptions...)
+			return true
+		})
+	if err != nil {
+		fmt.Printf("Error scanning table %s - Error: %s", g.subscriptionsTableName, err)
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.subscriptionsTableName, unmarshalErr)
+		return nil, unmarshalErr
+	}
+
+	return subscriptions, nil
+}
+
+func (g *Gateway) DeleteSubscription(subscriptionID string) error {
+	_, err := g.repository.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: &g.subscriptionsTableName,
+		Key:       map[string]*dynamodb.AttributeValue{"subscription_id": {S: aws.String(subscriptionID)}},
+	})
+	if err != nil {
+		fmt.Printf("Error deleting webhook subscription %s: %s\n", subscriptionID, err)
+		return err
+	}
+
+	return nil
+}
+
+// CreateDelivery writes the delivery only if its ID is not in use. It returns false when it is.
+func (g *Gateway) CreateDelivery(delivery *entities.WebhookDelivery) (bool, error) {
+	item, err := marshalDelivery(delivery)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName:           &g.deliveriesTableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(delivery_id)"),
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			return false, nil
+		}
+		fmt.Printf("Error saving %s %s: %s\n", g.deliveriesTableName, delivery.DeliveryID, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (g *Gateway) SaveDelivery(delivery *entities.WebhookDelivery) error {
+	item, err := marshalDelivery(delivery)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName: &g.deliveriesTableName,
+		Item:      item,
+	})
+	if err != nil {
+		fmt.Printf("Error saving %s %s: %s\n", g.deliveriesTableName, delivery.DeliveryID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *Gateway) GetDelivery(deliveryID string) (*entities.WebhookDelivery, error) {
+	var record deliveryRecord
+	found, err := g.get(g.deliveriesTableName, "delivery_id", deliveryID, &record)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	delivery, err := record.toEntity()
+	if err != nil {
+		fmt.Printf("Error Unmarshalling %s %s: %s\n", g.deliveriesTableName, deliveryID, err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// GetDeliveriesBySubscription returns the latest deliveries of a subscription, using the SubscriptionCreatedAtIndex
+func (g *Gateway) GetDeliveriesBySubscription(subscriptionID string, limit int) ([]entities.WebhookDelivery, error) {
+	return g.queryDeliveries(&dynamodb.QueryInput{
+		TableName:              &g.deliveriesTableName,
+		IndexName:              aws.String("SubscriptionCreatedAtIndex"),
+		KeyConditionExpression: aws.String("subscription_id = :subscription_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":subscription_id": {S: aws.String(subscriptionID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	})
+}
+
+// GetPendingDeliveries returns the pending deliveries due at now, the longest due first,
+// using the StatusNextAttemptAtIndex
+func (g *Gateway) GetPendingDeliveries(now time.Time, limit int) ([]entities.WebhookDelivery, error) {
+	return g.queryDeliveries(&dynamodb.QueryInput{
+		TableName:                &g.deliveriesTableName,
+		IndexName:                aws.String("StatusNextAttemptAtIndex"),
+		KeyConditionExpression:   aws.String("#status = :pending AND next_attempt_at <= :now"),
+		ExpressionAttributeNames: map[string]*string{"#status": aws.String("status")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pending": {S: aws.String(string(entities.PendingDeliveryStatus))},
+			":now":     {S: aws.String(og.FormatTimestamp(now))},
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int64(int64(limit)),
+	})
+}
+
+func (g *Gateway) queryDeliveries(query *dynamodb.QueryInput) ([]entities.WebhookDelivery, error) {
+	result, err := g.repository.Query(query)
+	if err != nil {
+		fmt.Printf("Error querying table %s - Error: %s", g.deliveriesTableName, err)
+		return nil, err
+	}
+
+	deliveries, err := unmarshalDeliveries(result.Items)
+	if err != nil {
+		fmt.Printf("Error Unmarshalling table data: %s\nerror: %s", g.deliveriesTableName, err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (g *Gateway) put(tableName, id string, value interface{}) error {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return err
+	}
+
+	_, err = g.repository.PutItem(&dynamodb.PutItemInput{
+		TableName: &tableName,
+		Item:      item,
+	})
+	if err != nil {
+		fmt.Printf("Error saving %s %s: %s\n", tableName, id, err)
+		return err
+	}
+
+	return nil
+}
+
+// get reads the item with the given key into value, it returns false when there is no such item
+func (g *Gateway) get(tableName, keyName, id string, value interface{}) (bool, error) {
+	result, err := g.repository.GetItem(&dynamodb.GetItemInput{
+		TableName: &tableName,
+		Key:       map[string]*dynamodb.AttributeValue{keyName: {S: aws.String(id)}},
+	})
+	if err != nil {
+		fmt.Printf("Error fetching %s %s: %s\n", tableName, id, err)
+		return false, err
+	}
+
+	if len(result.Item) == 0 {
+		return false, nil
+	}
+
+	if err := dynamodbattribute.UnmarshalMap(result.Item, value); err != nil {
+		fmt.Printf("Error Unmarshalling %s %s: %s\n", tableName, id, err)
+		return false, err
+	}
+
+	return true, nil
+}
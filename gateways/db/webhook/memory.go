@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// MemoryGateway keeps the webhook subscriptions and deliveries in memory, for local runs and tests
+type MemoryGateway struct {
+	mu            sync.RWMutex
+	subscriptions map[string]entities.WebhookSubscription
+	deliveries    map[string]entities.WebhookDelivery
+}
+
+func NewMemoryGateway() *MemoryGateway {
+	return &MemoryGateway{
+		subscriptions: map[string]entities.WebhookSubscription{},
+		deliveries:    map[string]entities.WebhookDelivery{},
+	}
+}
+
+func (g *MemoryGateway) SaveSubscription(subscription *entities.WebhookSubscription) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.subscriptions[subscription.SubscriptionID] = cloneSubscription(*subscription)
+	return nil
+}
+
+func (g *MemoryGateway) GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	subscription, exists := g.subscriptions[subscriptionID]
+	if !exists {
+		return nil, nil
+	}
+	subscription = cloneSubscription(subscription)
+	return &subscription, nil
+}
+
+func (g *MemoryGateway) GetAllSubscriptions() ([]entities.WebhookSubscription, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	subscriptions := []entities.WebhookSubscription{}
+	for _, subscription := range g.subscriptions {
+		subscriptions = append(subscriptions, cloneSubscription(subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+func (g *MemoryGateway) DeleteSubscription(subscriptionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.subscriptions, subscriptionID)
+	return nil
+}
+
+// CreateDelivery writes the delivery only if its ID is not in use. It returns false when it is.
+func (g *MemoryGateway) CreateDelivery(delivery *entities.WebhookDelivery) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.deliveries[delivery.DeliveryID]; exists {
+		return false, nil
+	}
+	g.deliveries[delivery.DeliveryID] = *delivery
+	return true, nil
+}
+
+func (g *MemoryGateway) SaveDelivery(delivery *entities.WebhookDelivery) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.deliveries[delivery.DeliveryID] = *delivery
+	return nil
+}
+
+func (g *MemoryGateway) GetDelivery(deliveryID string) (*entities.WebhookDelivery, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	delivery, exists := g.deliveries[deliveryID]
+	if !exists {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+// GetDeliveriesBySubscription returns the latest deliveries of a subscription
+func (g *MemoryGateway) GetDeliveriesBySubscription(subscriptionID string, limit int) ([]entities.WebhookDelivery, error) {
+	return g.deliveriesWhere(func(delivery entities.WebhookDelivery) bool {
+		return delivery.SubscriptionID == subscriptionID
+	}, func(a, b entities.WebhookDelivery) bool {
+		return b.CreatedAt.Before(a.CreatedAt)
+	}, limit), nil
+}
+
+// GetPendingDeliveries returns the pending deliveries due at now, the longest due first
+func (g *MemoryGateway) GetPendingDeliveries(now time.Time, limit int) ([]entities.WebhookDelivery, error) {
+	return g.deliveriesWhere(func(delivery entities.WebhookDelivery) bool {
+		return delivery.Status == entities.PendingDeliveryStatus && !delivery.NextAttemptAt.After(now)
+	}, func(a, b entities.WebhookDelivery) bool {
+		if !a.NextAttemptAt.Equal(b.NextAttemptAt) {
+			return a.NextAttemptAt.Before(b.NextAttemptAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}, limit), nil
+}
+
+// deliveriesWhere returns up to limit deliveries accepted by keep, sorted by less
+func (g *MemoryGateway) deliveriesWhere(keep func(entities.WebhookDelivery) bool,
+	less func(a, b entities.WebhookDelivery) bool, limit int) []entities.WebhookDelivery {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var deliveries []entities.WebhookDelivery
+	for _, delivery := range g.deliveries {
+		if keep(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return less(deliveries[i], deliveries[j])
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries
+}
+
+func cloneSubscription(subscription entities.WebhookSubscription) entities.WebhookSubscription {
+	subscription.EventTypes = append([]entities.EventType(nil), subscription.EventTypes...)
+	subscription.Statuses = append([]entities.Status(nil), subscription.Statuses...)
+	return subscription
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+)
+
+// deliveryRecord is a webhook delivery as it is stored in DynamoDB. Its timestamps are stored as the order
+// timestamps are, so the StatusNextAttemptAtIndex and SubscriptionCreatedAtIndex sort them in time order.
+type deliveryRecord struct {
+	DeliveryID     string                  `json:"delivery_id"`
+	SubscriptionID string                  `json:"subscription_id"`
+	EventID        string                  `json:"event_id"`
+	EventType      entities.EventType      `json:"event_type"`
+	Payload        string                  `json:"payload"`
+	Status         entities.DeliveryStatus `json:"status"`
+	Attempts       int                     `json:"attempts"`
+	ResponseStatus int                     `json:"response_status"`
+	LastError      string                  `json:"last_error"`
+	CreatedAt      string                  `json:"created_at"`
+	LastAttemptAt  string                  `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  string                  `json:"next_attempt_at"`
+	DeliveredAt    string                  `json:"delivered_at,omitempty"`
+}
+
+func marshalDelivery(delivery *entities.WebhookDelivery) (map[string]*dynamodb.AttributeValue, error) {
+	record := deliveryRecord{
+		DeliveryID:     delivery.DeliveryID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      og.FormatTimestamp(delivery.CreatedAt),
+		LastAttemptAt:  og.FormatTimestamp(delivery.LastAttemptAt),
+		NextAttemptAt:  og.FormatTimestamp(delivery.NextAttemptAt),
+		DeliveredAt:    og.FormatTimestamp(delivery.DeliveredAt),
+	}
+	// next_attempt_at is an index key, which cannot be empty. A delivery without it is due right away.
+	if record.NextAttemptAt == "" {
+		record.NextAttemptAt = record.CreatedAt
+	}
+
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		fmt.Println("Error marshaling to DynamoDB attribute map:", err)
+		return nil, err
+	}
+	return item, nil
+}
+
+// unmarshalDeliveries reads the items of webhook deliveries
+func unmarshalDeliveries(items []map[string]*dynamodb.AttributeValue) ([]entities.WebhookDelivery, error) {
+	var records []deliveryRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &records); err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]entities.WebhookDelivery, 0, len(records))
+	for _, record := range records {
+		delivery, err := record.toEntity()
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, nil
+}
+
+// toEntity returns the delivery of the record. It also reads the RFC 3339 timestamps of older deliveries.
+func (r *deliveryRecord) toEntity() (*entities.WebhookDelivery, error) {
+	delivery := &entities.WebhookDelivery{
+		DeliveryID:     r.DeliveryID,
+		SubscriptionID: r.SubscriptionID,
+		EventID:        r.EventID,
+		EventType:      r.EventType,
+		Payload:        r.Payload,
+		Status:         r.Status,
+		Attempts:       r.Attempts,
+		ResponseStatus: r.ResponseStatus,
+		LastError:      r.LastError,
+	}
+	timestamps := []struct {
+		value  string
+		target *time.Time
+	}{
+		{r.CreatedAt, &delivery.CreatedAt},
+		{r.LastAttemptAt, &delivery.LastAttemptAt},
+		{r.NextAttemptAt, &delivery.NextAttemptAt},
+		{r.DeliveredAt, &delivery.DeliveredAt},
+	}
+	for _, timestamp := range timestamps {
+		parsed, err := og.ParseTimestamp(timestamp.value)
+		if err != nil {
+			return nil, err
+		}
+		*timestamp.target = parsed
+	}
+	return delivery, nil
+}
@@ -4,14 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"strconv"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 )
 
 type GatewayInterface interface {
-	SendMessage(order *entities.Order) (*entities.Order, error)
+	Publish(event *entities.OrderEvent) error
 }
 
 type Gateway struct {
@@ -22,12 +23,14 @@ type Gateway struct {
 type GatewayMock struct {
 }
 
-func NewGateway(queueClient *sqs.SQS) GatewayInterface {
+// NewGateway publishes to the queue of queueURL. Without a queue client, in local runs without SQS,
+// the messages are recorded in memory: dropping them would let the outbox relay mark them as sent.
+func NewGateway(queueClient *sqs.SQS, queueURL string) GatewayInterface {
 	if queueClient == nil {
-		return NewGatewayMock()
+		return NewMemoryGateway()
 	}
 	return &Gateway{
-		queueURL: os.Getenv("QUEUE_URL"),
+		queueURL: queueURL,
 		queue:    queueClient,
 	}
 }
@@ -36,12 +39,14 @@ func NewGatewayMock() *GatewayMock {
 	return &GatewayMock{}
 }
 
-func (g *Gateway) SendMessage(order *entities.Order) (*entities.Order, error) {
+// Publish sends the event envelope as the message body. The type, schema version and correlation ID
+// also go as message attributes, so consumers can route the message without parsing the body.
+func (g *Gateway) Publish(event *entities.OrderEvent) error {
 	// Convert the struct to a JSON string
-	jsonString, err := json.Marshal(order)
+	jsonString, err := json.Marshal(event)
 	if err != nil {
-		fmt.Printf("Error parsing order to json string: %s\n", err)
-		return nil, err
+		fmt.Printf("Error parsing event to json string: %s\n", err)
+		return err
 	}
 	stringMessage := string(jsonString)
 	fmt.Printf("Sending message: %s\n", jsonString)
@@ -50,15 +55,33 @@ func (g *Gateway) SendMessage(order *entities.Order) (*entities.Order, error) {
 	message := &sqs.SendMessageInput{
 		QueueUrl:    &g.queueURL,
 		MessageBody: &stringMessage,
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"event_type": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(string(event.Type)),
+			},
+			"schema_version": {
+				DataType:    aws.String("Number"),
+				StringValue: aws.String(strconv.Itoa(event.SchemaVersion)),
+			},
+			"correlation_id": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(event.CorrelationID),
+			},
+		},
 	}
 
 	log.Printf("Enviando Mensagem: %v\n", message)
 	messageResult, err := g.queue.SendMessage(message)
+	if err != nil {
+		log.Printf("Erro ao enviar mensagem: %s\n", err)
+		return err
+	}
 	log.Printf("Mensagem enviada Mensagem: %v\n", messageResult)
 
-	return order, nil
+	return nil
 }
 
-func (g *GatewayMock) SendMessage(order *entities.Order) (*entities.Order, error) {
-	return order, nil
+func (g *GatewayMock) Publish(event *entities.OrderEvent) error {
+	return nil
 }
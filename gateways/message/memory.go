@@ -0,0 +1,35 @@
+package message
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// MemoryGateway records the published events instead of sending them, for local runs and tests
+type MemoryGateway struct {
+	mu       sync.RWMutex
+	messages []entities.OrderEvent
+}
+
+func NewMemoryGateway() *MemoryGateway {
+	return &MemoryGateway{}
+}
+
+func (g *MemoryGateway) Publish(event *entities.OrderEvent) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fmt.Printf("Recording message: %s %s\n", event.Type, event.EventID)
+	g.messages = append(g.messages, *event)
+	return nil
+}
+
+// Messages returns the events published so far, oldest first
+func (g *MemoryGateway) Messages() []entities.OrderEvent {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return append([]entities.OrderEvent(nil), g.messages...)
+}
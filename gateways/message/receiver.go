@@ -0,0 +1,58 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+const longPollingSeconds = 20
+
+type Receiver struct {
+	queueURL string
+	queue    *sqs.SQS
+}
+
+func NewReceiver(queueClient *sqs.SQS, queueURL string) *Receiver {
+	return &Receiver{
+		queueURL: queueURL,
+		queue:    queueClient,
+	}
+}
+
+// ReceiveMessages long-polls the queue for up to maxMessages messages
+func (r *Receiver) ReceiveMessages(maxMessages int) ([]entities.QueueMessage, error) {
+	result, err := r.queue.ReceiveMessage(&sqs.ReceiveMessageInput{
+		QueueUrl:            &r.queueURL,
+		MaxNumberOfMessages: aws.Int64(int64(maxMessages)),
+		WaitTimeSeconds:     aws.Int64(longPollingSeconds),
+	})
+	if err != nil {
+		fmt.Printf("Error receiving messages from %s: %s\n", r.queueURL, err)
+		return nil, err
+	}
+
+	var messages []entities.QueueMessage
+	for _, message := range result.Messages {
+		messages = append(messages, entities.QueueMessage{
+			MessageID:     aws.StringValue(message.MessageId),
+			Body:          aws.StringValue(message.Body),
+			ReceiptHandle: aws.StringValue(message.ReceiptHandle),
+		})
+	}
+
+	return messages, nil
+}
+
+func (r *Receiver) DeleteMessage(receiptHandle string) error {
+	_, err := r.queue.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      &r.queueURL,
+		ReceiptHandle: &receiptHandle,
+	})
+	if err != nil {
+		fmt.Printf("Error deleting message from %s: %s\n", r.queueURL, err)
+	}
+	return err
+}
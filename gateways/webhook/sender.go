@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address partners cannot own,
+// so subscriptions cannot be used to reach the internal network
+var ErrPrivateAddress = errors.New("webhook URL resolves to a loopback, link-local or private address")
+
+// Sender posts webhook deliveries to the partner URLs. Redirects are not followed,
+// and only public addresses are dialed.
+type Sender struct {
+	client *http.Client
+	// AllowPrivateAddresses dials any address, for tests whose receivers listen on loopback
+	AllowPrivateAddresses bool
+}
+
+func NewSender() *Sender {
+	sender := &Sender{}
+	dialer := &net.Dialer{Timeout: defaultTimeout, Control: sender.checkAddress}
+	sender.client = &http.Client{
+		Timeout: defaultTimeout,
+		// Proxies from the environment are not used, the address checked must be the one of the partner
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: defaultTimeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return sender
+}
+
+// checkAddress runs before every connection, once the host name is resolved,
+// so host names resolving to private addresses are rejected too
+func (s *Sender) checkAddress(network, address string, _ syscall.RawConn) error {
+	if s.AllowPrivateAddresses {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !util.IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// Send posts the body with the given headers and returns the response status code.
+// An error means no response was received. A redirect is returned as it is, its status code is not a success.
+func (s *Sender) Send(url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,139 @@
+### DynamoDB Tables ###
+# The orders table predates this repository and is not managed here, see the README.
+# The attribute names, keys and indexes match external/dynamodb.go, which creates the same tables locally.
+
+resource "aws_dynamodb_table" "items" {
+  name         = "items"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "item_id"
+
+  attribute {
+    name = "item_id"
+    type = "S"
+  }
+
+  tags = {
+    infra   = "dynamodb-items"
+    service = "pedidos"
+  }
+}
+
+resource "aws_dynamodb_table" "orders_outbox" {
+  name         = "orders_outbox"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "event_id"
+
+  attribute {
+    name = "event_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "status"
+    type = "S"
+  }
+
+  attribute {
+    name = "next_attempt_at"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "StatusNextAttemptAtIndex"
+    hash_key        = "status"
+    range_key       = "next_attempt_at"
+    projection_type = "ALL"
+  }
+
+  tags = {
+    infra   = "dynamodb-orders-outbox"
+    service = "pedidos"
+  }
+}
+
+resource "aws_dynamodb_table" "idempotency_keys" {
+  name         = "idempotency_keys"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "idempotency_key"
+
+  attribute {
+    name = "idempotency_key"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+
+  tags = {
+    infra   = "dynamodb-idempotency-keys"
+    service = "pedidos"
+  }
+}
+
+resource "aws_dynamodb_table" "webhook_subscriptions" {
+  name         = "webhook_subscriptions"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "subscription_id"
+
+  attribute {
+    name = "subscription_id"
+    type = "S"
+  }
+
+  tags = {
+    infra   = "dynamodb-webhook-subscriptions"
+    service = "pedidos"
+  }
+}
+
+resource "aws_dynamodb_table" "webhook_deliveries" {
+  name         = "webhook_deliveries"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "delivery_id"
+
+  attribute {
+    name = "delivery_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "status"
+    type = "S"
+  }
+
+  attribute {
+    name = "subscription_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "created_at"
+    type = "S"
+  }
+
+  attribute {
+    name = "next_attempt_at"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "StatusNextAttemptAtIndex"
+    hash_key        = "status"
+    range_key       = "next_attempt_at"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "SubscriptionCreatedAtIndex"
+    hash_key        = "subscription_id"
+    range_key       = "created_at"
+    projection_type = "ALL"
+  }
+
+  tags = {
+    infra   = "dynamodb-webhook-deliveries"
+    service = "pedidos"
+  }
+}
@@ -48,7 +48,7 @@ resource "aws_lb_listener_rule" "listener_pedidos_api" {
 
   condition {
     path_pattern {
-      values = ["/pedidos*"]
+      values = ["/pedidos*", "/itens*", "/cozinha*", "/webhooks*"]
     }
   }
 
@@ -94,7 +94,8 @@ resource "aws_ecs_task_definition" "task_definition_pedidos_api" {
 
       environment = [
         { "name" : "IS_LOCAL", "value" : "false" },
-        { "name" : "QUEUE_URL", "value" : var.sqs_url }
+        { "name" : "QUEUE_URL", "value" : var.sqs_url },
+        { "name" : "PAYMENT_RESULTS_QUEUE_URL", "value" : var.sqs_payment_results_url }
       ]
 
       logConfiguration = {
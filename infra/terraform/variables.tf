@@ -77,3 +77,10 @@ variable "sqs_url" {
   sensitive   = true
   default     = ""
 }
+
+variable "sqs_payment_results_url" {
+  description = "SQS Payment Results URL"
+  type        = string
+  sensitive   = true
+  default     = ""
+}
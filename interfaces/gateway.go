@@ -1,18 +1,63 @@
 package interfaces
 
 import (
+	"time"
+
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 )
 
 type OrderGatewayI interface {
 	Save(order *entities.Order) (*entities.Order, error)
-	Update(orderID string, order *entities.Order) (*entities.Order, error)
-	Delete(order *entities.Order) error
+	Update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error)
 	GetByID(orderID string) (*entities.Order, error)
-	GetAll() (*[]entities.Order, error)
-	GetAllByClientID(clientID string) (*[]entities.Order, error)
+	GetAll(filter entities.OrderFilter) (*entities.OrderPage, error)
+}
+
+type ItemGatewayI interface {
+	Save(item *entities.Item) (*entities.Item, error)
+	GetByID(itemID string) (*entities.Item, error)
+	GetAll() (*[]entities.Item, error)
+}
+
+type OutboxGatewayI interface {
+	GetPending(now time.Time, limit int) ([]entities.OutboxEvent, error)
+	Save(event *entities.OutboxEvent) error
 }
 
 type QueueGatewayI interface {
-	SendMessage(order *entities.Order) (*entities.Order, error)
+	Publish(event *entities.OrderEvent) error
+}
+
+type MessageReceiverI interface {
+	ReceiveMessages(maxMessages int) ([]entities.QueueMessage, error)
+	DeleteMessage(receiptHandle string) error
+}
+
+type OrderBroadcasterI interface {
+	Publish(event entities.OrderEvent)
+	Subscribe(filter entities.StreamFilter, lastEventID uint64) *entities.OrderSubscription
+}
+
+type IdempotencyGatewayI interface {
+	Create(record *entities.IdempotencyRecord, now time.Time) (bool, error)
+	GetByKey(key string) (*entities.IdempotencyRecord, error)
+	TakeOver(record *entities.IdempotencyRecord, lockedUntil int64) (bool, error)
+	Complete(record *entities.IdempotencyRecord) (bool, error)
+	Release(record *entities.IdempotencyRecord) (bool, error)
+}
+
+type WebhookGatewayI interface {
+	SaveSubscription(subscription *entities.WebhookSubscription) error
+	GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error)
+	GetAllSubscriptions() ([]entities.WebhookSubscription, error)
+	DeleteSubscription(subscriptionID string) error
+	CreateDelivery(delivery *entities.WebhookDelivery) (bool, error)
+	SaveDelivery(delivery *entities.WebhookDelivery) error
+	GetDelivery(deliveryID string) (*entities.WebhookDelivery, error)
+	GetDeliveriesBySubscription(subscriptionID string, limit int) ([]entities.WebhookDelivery, error)
+	GetPendingDeliveries(now time.Time, limit int) ([]entities.WebhookDelivery, error)
+}
+
+type WebhookSenderI interface {
+	Send(url string, headers map[string]string, body []byte) (int, error)
 }
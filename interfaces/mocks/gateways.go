@@ -0,0 +1,248 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+type OrderGateway struct {
+	mock.Mock
+}
+
+func (_m *OrderGateway) Save(order *entities.Order) (*entities.Order, error) {
+	ret := _m.Called(order)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderGateway) Update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error) {
+	ret := _m.Called(orderID, order, fields)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderGateway) GetByID(orderID string) (*entities.Order, error) {
+	ret := _m.Called(orderID)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderGateway) GetAll(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	ret := _m.Called(filter)
+
+	var r0 *entities.OrderPage
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.OrderPage)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+type ItemGateway struct {
+	mock.Mock
+}
+
+func (_m *ItemGateway) Save(item *entities.Item) (*entities.Item, error) {
+	ret := _m.Called(item)
+
+	var r0 *entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemGateway) GetByID(itemID string) (*entities.Item, error) {
+	ret := _m.Called(itemID)
+
+	var r0 *entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemGateway) GetAll() (*[]entities.Item, error) {
+	ret := _m.Called()
+
+	var r0 *[]entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*[]entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+type QueueGateway struct {
+	mock.Mock
+}
+
+func (_m *QueueGateway) Publish(event *entities.OrderEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error = ret.Error(0)
+
+	return r0
+}
+
+type IdempotencyGateway struct {
+	mock.Mock
+}
+
+func (_m *IdempotencyGateway) Create(record *entities.IdempotencyRecord, now time.Time) (bool, error) {
+	ret := _m.Called(record, now)
+
+	var r0 bool = ret.Bool(0)
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *IdempotencyGateway) GetByKey(key string) (*entities.IdempotencyRecord, error) {
+	ret := _m.Called(key)
+
+	var r0 *entities.IdempotencyRecord
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.IdempotencyRecord)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *IdempotencyGateway) TakeOver(record *entities.IdempotencyRecord, lockedUntil int64) (bool, error) {
+	ret := _m.Called(record, lockedUntil)
+
+	var r0 bool = ret.Bool(0)
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *IdempotencyGateway) Complete(record *entities.IdempotencyRecord) (bool, error) {
+	ret := _m.Called(record)
+
+	var r0 bool = ret.Bool(0)
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *IdempotencyGateway) Release(record *entities.IdempotencyRecord) (bool, error) {
+	ret := _m.Called(record)
+
+	var r0 bool = ret.Bool(0)
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+type WebhookGateway struct {
+	mock.Mock
+}
+
+func (_m *WebhookGateway) SaveSubscription(subscription *entities.WebhookSubscription) error {
+	ret := _m.Called(subscription)
+	return ret.Error(0)
+}
+
+func (_m *WebhookGateway) GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error) {
+	ret := _m.Called(subscriptionID)
+
+	var r0 *entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookGateway) GetAllSubscriptions() ([]entities.WebhookSubscription, error) {
+	ret := _m.Called()
+
+	var r0 []entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookGateway) DeleteSubscription(subscriptionID string) error {
+	ret := _m.Called(subscriptionID)
+	return ret.Error(0)
+}
+
+func (_m *WebhookGateway) CreateDelivery(delivery *entities.WebhookDelivery) (bool, error) {
+	ret := _m.Called(delivery)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (_m *WebhookGateway) SaveDelivery(delivery *entities.WebhookDelivery) error {
+	ret := _m.Called(delivery)
+	return ret.Error(0)
+}
+
+func (_m *WebhookGateway) GetDelivery(deliveryID string) (*entities.WebhookDelivery, error) {
+	ret := _m.Called(deliveryID)
+
+	var r0 *entities.WebhookDelivery
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookDelivery)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookGateway) GetDeliveriesBySubscription(subscriptionID string, limit int) ([]entities.WebhookDelivery, error) {
+	ret := _m.Called(subscriptionID, limit)
+
+	var r0 []entities.WebhookDelivery
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]entities.WebhookDelivery)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookGateway) GetPendingDeliveries(now time.Time, limit int) ([]entities.WebhookDelivery, error) {
+	ret := _m.Called(now, limit)
+
+	var r0 []entities.WebhookDelivery
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]entities.WebhookDelivery)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
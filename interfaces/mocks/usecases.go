@@ -9,12 +9,12 @@ type OrderUseCase struct {
 	mock.Mock
 }
 
-func (_m *OrderUseCase) List(clientID, status string) (*[]entities.Order, error) {
-	ret := _m.Called(clientID, status)
+func (_m *OrderUseCase) List(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	ret := _m.Called(filter)
 
-	var r0 *[]entities.Order
+	var r0 *entities.OrderPage
 	if ret.Get(0) != nil {
-		r0 = ret.Get(0).(*[]entities.Order)
+		r0 = ret.Get(0).(*entities.OrderPage)
 	}
 	var r1 error = ret.Error(1)
 
@@ -45,8 +45,8 @@ func (_m *OrderUseCase) GetByID(orderID string) (*entities.Order, error) {
 	return r0, r1
 }
 
-func (_m *OrderUseCase) Update(orderID string, updatedOrder *entities.Order) (*entities.Order, error) {
-	ret := _m.Called(orderID, updatedOrder)
+func (_m *OrderUseCase) Update(orderID string, updatedOrder *entities.Order, actor, reason string) (*entities.Order, error) {
+	ret := _m.Called(orderID, updatedOrder, actor, reason)
 
 	var r0 *entities.Order
 	if ret.Get(0) != nil {
@@ -57,8 +57,9 @@ func (_m *OrderUseCase) Update(orderID string, updatedOrder *entities.Order) (*e
 	return r0, r1
 }
 
-func (_m *OrderUseCase) UpdateOrderStatus(orderID string, orderStatus entities.Status) (*entities.Order, error) {
-	ret := _m.Called(orderID, orderStatus)
+func (_m *OrderUseCase) UpdateOrderStatus(orderID string, orderStatus entities.Status, actor, reason string,
+	version int) (*entities.Order, error) {
+	ret := _m.Called(orderID, orderStatus, actor, reason, version)
 
 	var r0 *entities.Order
 	if ret.Get(0) != nil {
@@ -69,10 +70,250 @@ func (_m *OrderUseCase) UpdateOrderStatus(orderID string, orderStatus entities.S
 	return r0, r1
 }
 
-func (_m *OrderUseCase) Delete(orderID string) error {
-	ret := _m.Called(orderID)
+func (_m *OrderUseCase) Cancel(orderID, actor, reason string, version int) (*entities.Order, error) {
+	ret := _m.Called(orderID, actor, reason, version)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderUseCase) KitchenQueue() (*entities.KitchenQueue, error) {
+	ret := _m.Called()
+
+	var r0 *entities.KitchenQueue
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.KitchenQueue)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderUseCase) Claim(orderID, station, actor string, version int) (*entities.Order, error) {
+	ret := _m.Called(orderID, station, actor, version)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderUseCase) Subscribe(filter entities.StreamFilter, lastEventID uint64) (*entities.OrderSubscription, error) {
+	ret := _m.Called(filter, lastEventID)
+
+	var r0 *entities.OrderSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.OrderSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *OrderUseCase) Delete(orderID, actor string) error {
+	ret := _m.Called(orderID, actor)
+
+	var r0 error = ret.Error(0)
+
+	return r0
+}
+
+func (_m *OrderUseCase) Restore(orderID, actor string) (*entities.Order, error) {
+	ret := _m.Called(orderID, actor)
+
+	var r0 *entities.Order
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Order)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+type ItemUseCase struct {
+	mock.Mock
+}
+
+func (_m *ItemUseCase) List() (*[]entities.Item, error) {
+	ret := _m.Called()
+
+	var r0 *[]entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*[]entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemUseCase) Create(item *entities.Item) (*entities.Item, error) {
+	ret := _m.Called(item)
+
+	var r0 *entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemUseCase) GetByID(itemID string) (*entities.Item, error) {
+	ret := _m.Called(itemID)
+
+	var r0 *entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemUseCase) Update(itemID string, updatedItem *entities.Item) (*entities.Item, error) {
+	ret := _m.Called(itemID, updatedItem)
+
+	var r0 *entities.Item
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.Item)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *ItemUseCase) Delete(itemID string) error {
+	ret := _m.Called(itemID)
+
+	var r0 error = ret.Error(0)
+
+	return r0
+}
+
+type IdempotencyUseCase struct {
+	mock.Mock
+}
+
+func (_m *IdempotencyUseCase) Start(clientID, key string, request []byte) (*entities.IdempotencyRecord, error) {
+	ret := _m.Called(clientID, key, request)
+
+	var r0 *entities.IdempotencyRecord
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.IdempotencyRecord)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *IdempotencyUseCase) Complete(record *entities.IdempotencyRecord, statusCode int, contentType string,
+	response []byte) error {
+	ret := _m.Called(record, statusCode, contentType, response)
 
 	var r0 error = ret.Error(0)
 
 	return r0
 }
+
+func (_m *IdempotencyUseCase) Release(record *entities.IdempotencyRecord) error {
+	ret := _m.Called(record)
+
+	var r0 error = ret.Error(0)
+
+	return r0
+}
+
+type WebhookUseCase struct {
+	mock.Mock
+}
+
+func (_m *WebhookUseCase) CreateSubscription(subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	ret := _m.Called(subscription)
+
+	var r0 *entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error) {
+	ret := _m.Called(subscriptionID)
+
+	var r0 *entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) ListSubscriptions() ([]entities.WebhookSubscription, error) {
+	ret := _m.Called()
+
+	var r0 []entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) UpdateSubscription(subscriptionID string,
+	subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	ret := _m.Called(subscriptionID, subscription)
+
+	var r0 *entities.WebhookSubscription
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookSubscription)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) DeleteSubscription(subscriptionID string) error {
+	ret := _m.Called(subscriptionID)
+	return ret.Error(0)
+}
+
+func (_m *WebhookUseCase) ListDeliveries(subscriptionID string, limit int) ([]entities.WebhookDelivery, error) {
+	ret := _m.Called(subscriptionID, limit)
+
+	var r0 []entities.WebhookDelivery
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]entities.WebhookDelivery)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) Redeliver(subscriptionID, deliveryID string) (*entities.WebhookDelivery, error) {
+	ret := _m.Called(subscriptionID, deliveryID)
+
+	var r0 *entities.WebhookDelivery
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*entities.WebhookDelivery)
+	}
+	var r1 error = ret.Error(1)
+
+	return r0, r1
+}
+
+func (_m *WebhookUseCase) Dispatch(event *entities.OrderEvent) error {
+	ret := _m.Called(event)
+	return ret.Error(0)
+}
@@ -5,10 +5,40 @@ import (
 )
 
 type OrderUseCase interface {
-	List(clientID, status string) (*[]entities.Order, error)
+	List(filter entities.OrderFilter) (*entities.OrderPage, error)
 	Create(order *entities.Order) (*entities.Order, error)
 	GetByID(orderID string) (*entities.Order, error)
-	Update(orderID string, updatedOrder *entities.Order) (*entities.Order, error)
-	UpdateOrderStatus(orderID string, orderStatus entities.Status) (*entities.Order, error)
-	Delete(orderID string) error
+	Update(orderID string, updatedOrder *entities.Order, actor, reason string) (*entities.Order, error)
+	UpdateOrderStatus(orderID string, orderStatus entities.Status, actor, reason string, version int) (*entities.Order, error)
+	Cancel(orderID, actor, reason string, version int) (*entities.Order, error)
+	Delete(orderID, actor string) error
+	Restore(orderID, actor string) (*entities.Order, error)
+	KitchenQueue() (*entities.KitchenQueue, error)
+	Claim(orderID, station, actor string, version int) (*entities.Order, error)
+	Subscribe(filter entities.StreamFilter, lastEventID uint64) (*entities.OrderSubscription, error)
+}
+
+type ItemUseCase interface {
+	List() (*[]entities.Item, error)
+	Create(item *entities.Item) (*entities.Item, error)
+	GetByID(itemID string) (*entities.Item, error)
+	Update(itemID string, updatedItem *entities.Item) (*entities.Item, error)
+	Delete(itemID string) error
+}
+
+type IdempotencyUseCase interface {
+	Start(clientID, key string, request []byte) (*entities.IdempotencyRecord, error)
+	Complete(record *entities.IdempotencyRecord, statusCode int, contentType string, response []byte) error
+	Release(record *entities.IdempotencyRecord) error
+}
+
+type WebhookUseCase interface {
+	CreateSubscription(subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error)
+	GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error)
+	ListSubscriptions() ([]entities.WebhookSubscription, error)
+	UpdateSubscription(subscriptionID string, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error)
+	DeleteSubscription(subscriptionID string) error
+	ListDeliveries(subscriptionID string, limit int) ([]entities.WebhookDelivery, error)
+	Redeliver(subscriptionID, deliveryID string) (*entities.WebhookDelivery, error)
+	Dispatch(event *entities.OrderEvent) error
 }
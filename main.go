@@ -1,14 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
 )
 
 import (
-	_ "github.com/lib/pq"
 	"github.com/postech-soat2-grupo16/pedidos-api/api"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
 	_ "github.com/postech-soat2-grupo16/pedidos-api/docs"
 )
 
@@ -24,12 +25,23 @@ import (
 // @license.name	Apache 2.0
 // @license.url	http://www.apache.org/licenses/LICENSE-2.0.html
 func main() {
-	db := api.SetupDB()
-	queue := api.SetupQueue()
-	r := api.SetupRouter(db, queue)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	db := api.SetupDB(cfg)
+	queue := api.SetupQueue(cfg)
+	r := api.SetupRouter(cfg, db, queue)
+
+	go api.SetupOutboxRelay(cfg, db, queue).Run(context.Background())
+	go api.SetupWebhookDeliverer(cfg, db).Run(context.Background())
+	if paymentConsumer := api.SetupPaymentConsumer(cfg, db, queue); paymentConsumer != nil {
+		go paymentConsumer.Run(context.Background())
+	}
 
 	server := &http.Server{
-		Addr:              ":8000",
+		Addr:              cfg.Server.Address,
 		ReadHeaderTimeout: 3 & time.Second,
 		Handler:           r,
 	}
@@ -0,0 +1,419 @@
+// Package conformance holds the behavior every implementation of a gateway interface must have,
+// as test suites run against each implementation.
+package conformance
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewOrderGateway returns an empty gateway for one test of the suite
+type NewOrderGateway func(t *testing.T) interfaces.OrderGatewayI
+
+// baseTime has no sub-microsecond part, the precision every backend keeps
+var baseTime = time.Date(2024, 5, 10, 12, 0, 0, 123456000, time.UTC)
+
+// RunOrderGatewaySuite runs the OrderGatewayI behavior against the gateways returned by newGateway
+func RunOrderGatewaySuite(t *testing.T, newGateway NewOrderGateway) {
+	t.Run("SaveAndGetByID", func(t *testing.T) { testSaveAndGetByID(t, newGateway(t)) })
+	t.Run("NotFoundIsNil", func(t *testing.T) { testNotFoundIsNil(t, newGateway(t)) })
+	t.Run("SaveIsConditionedOnVersion", func(t *testing.T) { testSaveIsConditionedOnVersion(t, newGateway(t)) })
+	t.Run("UpdateIsConditionedOnVersion", func(t *testing.T) { testUpdateIsConditionedOnVersion(t, newGateway(t)) })
+	t.Run("UpdateChangesOnlyTheGivenFields", func(t *testing.T) { testUpdateChangesOnlyTheGivenFields(t, newGateway(t)) })
+	t.Run("GetAllByClient", func(t *testing.T) { testGetAllByClient(t, newGateway(t)) })
+	t.Run("GetAllByStatus", func(t *testing.T) { testGetAllByStatus(t, newGateway(t)) })
+	t.Run("GetAllByItemAndCreationTime", func(t *testing.T) { testGetAllByItemAndCreationTime(t, newGateway(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, newGateway(t)) })
+	t.Run("InvalidCursor", func(t *testing.T) { testInvalidCursor(t, newGateway(t)) })
+	t.Run("SoftDeletion", func(t *testing.T) { testSoftDeletion(t, newGateway(t)) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, newGateway(t)) })
+	t.Run("ConcurrentCreations", func(t *testing.T) { testConcurrentCreations(t, newGateway(t)) })
+}
+
+// NewOrder returns a valid order, never saved, created minutes after the suite base time
+func NewOrder(orderID, clientID string, minutes int) *entities.Order {
+	createdAt := baseTime.Add(time.Duration(minutes) * time.Minute)
+	return &entities.Order{
+		OrderID:  orderID,
+		ClientID: clientID,
+		Status:   entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{
+			{ItemID: "1", Name: "X-Burguer", Category: "lanche", Description: "pão e carne", Price: 20.5, Quantity: 2, LineTotal: 4100},
+			{ItemID: "2", Name: "Suco", Category: "bebida", Price: 7, Quantity: 1, LineTotal: 700},
+		},
+		Notes:    "sem cebola",
+		Subtotal: 4800,
+		Discount: 300,
+		Total:    4500,
+		StatusHistory: []entities.StatusChange{
+			{To: entities.CreatedOrdersStatus, ChangedAt: createdAt, Actor: clientID, Reason: "order created"},
+		},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func save(t *testing.T, gateway interfaces.OrderGatewayI, order *entities.Order) *entities.Order {
+	saved, err := gateway.Save(order)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	return saved
+}
+
+func assertConflict(t *testing.T, err error) {
+	domainErr, ok := util.AsErrorDomain(err)
+	if assert.True(t, ok, "expected a conflict error, got %v", err) {
+		assert.Equal(t, util.ConflictCategory, domainErr.Category)
+	}
+}
+
+func orderIDs(page *entities.OrderPage) []string {
+	ids := []string{}
+	for _, order := range page.Orders {
+		ids = append(ids, order.OrderID)
+	}
+	return ids
+}
+
+func testSaveAndGetByID(t *testing.T, gateway interfaces.OrderGatewayI) {
+	order := NewOrder("1", "c1", 0)
+	claimedAt := baseTime.Add(time.Minute)
+	order.Station = "grill"
+	order.ClaimedAt = &claimedAt
+
+	saved := save(t, gateway, order)
+	assert.Equal(t, 1, saved.Version)
+
+	fetched, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	require.NotNil(t, fetched)
+
+	expected := NewOrder("1", "c1", 0)
+	expected.Station = "grill"
+	expected.ClaimedAt = &claimedAt
+	expected.Version = 1
+	assert.Equal(t, expected, fetched)
+}
+
+func testNotFoundIsNil(t *testing.T, gateway interfaces.OrderGatewayI) {
+	fetched, err := gateway.GetByID("missing")
+	assert.NoError(t, err)
+	assert.Nil(t, fetched)
+
+	updated, err := gateway.Update("missing", NewOrder("missing", "c1", 0), entities.OrderNotesField)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+}
+
+func testSaveIsConditionedOnVersion(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("1", "c1", 0))
+
+	_, err := gateway.Save(NewOrder("1", "c2", 0))
+	assertConflict(t, err)
+
+	stale, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	current, err := gateway.GetByID("1")
+	require.NoError(t, err)
+
+	current.Notes = "first"
+	saved := save(t, gateway, current)
+	assert.Equal(t, 2, saved.Version)
+
+	stale.Notes = "second"
+	_, err = gateway.Save(stale)
+	assertConflict(t, err)
+
+	fetched, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	assert.Equal(t, "first", fetched.Notes)
+	assert.Equal(t, "c1", fetched.ClientID)
+}
+
+func testUpdateIsConditionedOnVersion(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("1", "c1", 0))
+	stale, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	current, err := gateway.GetByID("1")
+	require.NoError(t, err)
+
+	current.Notes = "first"
+	updated, err := gateway.Update("1", current, entities.OrderNotesField)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	stale.Notes = "second"
+	_, err = gateway.Update("1", stale, entities.OrderNotesField)
+	assertConflict(t, err)
+}
+
+func testUpdateChangesOnlyTheGivenFields(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("1", "c1", 0))
+	order, err := gateway.GetByID("1")
+	require.NoError(t, err)
+
+	updatedAt := baseTime.Add(time.Hour)
+	order.Notes = "bem passado"
+	order.OrderedItems = order.OrderedItems[:1]
+	order.Subtotal, order.Total = 4100, 3800
+	order.ChangeStatus(entities.CanceledOrderStatus, "c1", "changed my mind", updatedAt)
+	order.UpdatedAt = updatedAt
+
+	updated, err := gateway.Update("1", order, entities.OrderItemsField)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, updated.Version)
+	assert.Equal(t, updatedAt, updated.UpdatedAt)
+	assert.Len(t, updated.OrderedItems, 1)
+	assert.Equal(t, entities.Cents(3800), updated.Total)
+	assert.Equal(t, "sem cebola", updated.Notes)
+	assert.Equal(t, entities.CreatedOrdersStatus, updated.Status)
+	assert.Len(t, updated.StatusHistory, 1)
+
+	fetched, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	assert.Equal(t, updated, fetched)
+}
+
+func testGetAllByClient(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("b", "c1", 2))
+	save(t, gateway, NewOrder("a", "c1", 1))
+	save(t, gateway, NewOrder("c", "c1", 3))
+	save(t, gateway, NewOrder("other", "c2", 0))
+
+	page, err := gateway.GetAll(entities.OrderFilter{ClientID: "c1", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, orderIDs(page))
+	assert.Empty(t, page.NextCursor)
+	assert.Len(t, page.Orders[0].OrderedItems, 2)
+
+	page, err = gateway.GetAll(entities.OrderFilter{ClientID: "c1", Descending: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, orderIDs(page))
+
+	page, err = gateway.GetAll(entities.OrderFilter{ClientID: "nobody", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, orderIDs(page))
+}
+
+func testGetAllByStatus(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("created", "c1", 0))
+	ready := NewOrder("ready", "c2", 1)
+	ready.Status = entities.ReadyOrderStatus
+	save(t, gateway, ready)
+	canceled := NewOrder("canceled", "c1", 2)
+	canceled.Status = entities.CanceledOrderStatus
+	save(t, gateway, canceled)
+
+	page, err := gateway.GetAll(entities.OrderFilter{
+		Statuses: []entities.Status{entities.CanceledOrderStatus, entities.CreatedOrdersStatus},
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created", "canceled"}, orderIDs(page))
+
+	page, err = gateway.GetAll(entities.OrderFilter{ClientID: "c1", Statuses: []entities.Status{entities.CanceledOrderStatus}, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"canceled"}, orderIDs(page))
+
+	page, err = gateway.GetAll(entities.OrderFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created", "ready", "canceled"}, orderIDs(page))
+}
+
+func testGetAllByItemAndCreationTime(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("early", "c1", 0))
+	juice := NewOrder("juice", "c1", 1)
+	juice.OrderedItems = juice.OrderedItems[1:]
+	save(t, gateway, juice)
+	save(t, gateway, NewOrder("middle", "c1", 2))
+	save(t, gateway, NewOrder("late", "c2", 3))
+
+	page, err := gateway.GetAll(entities.OrderFilter{ItemID: "1", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"early", "middle", "late"}, orderIDs(page))
+
+	// Both bounds are inclusive
+	page, err = gateway.GetAll(entities.OrderFilter{
+		CreatedFrom: baseTime.Add(time.Minute),
+		CreatedTo:   baseTime.Add(3 * time.Minute),
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"juice", "middle", "late"}, orderIDs(page))
+
+	page, err = gateway.GetAll(entities.OrderFilter{ClientID: "c1", ItemID: "1", CreatedFrom: baseTime.Add(time.Minute), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"middle"}, orderIDs(page))
+}
+
+// testPagination pages through orders of several statuses and clients, a page may hold fewer orders than the limit
+func testPagination(t *testing.T, gateway interfaces.OrderGatewayI) {
+	statuses := []entities.Status{entities.CreatedOrdersStatus, entities.ReadyOrderStatus, entities.CanceledOrderStatus}
+	var expected []string
+	for i := 0; i < 8; i++ {
+		order := NewOrder(fmt.Sprintf("order-%d", i), fmt.Sprintf("c%d", i%2), i)
+		order.Status = statuses[i%len(statuses)]
+		save(t, gateway, order)
+		expected = append(expected, order.OrderID)
+	}
+
+	for _, filter := range []entities.OrderFilter{
+		{Limit: 3},
+		{Limit: 3, Statuses: statuses},
+		{Limit: 2, ClientID: "c0"},
+	} {
+		want := expected
+		if filter.ClientID != "" {
+			want = []string{"order-0", "order-2", "order-4", "order-6"}
+		}
+		assert.Equal(t, want, pageThrough(t, gateway, filter), "ascending %+v", filter)
+
+		filter.Descending = true
+		var reversed []string
+		for i := len(want) - 1; i >= 0; i-- {
+			reversed = append(reversed, want[i])
+		}
+		assert.Equal(t, reversed, pageThrough(t, gateway, filter), "descending %+v", filter)
+	}
+}
+
+func pageThrough(t *testing.T, gateway interfaces.OrderGatewayI, filter entities.OrderFilter) []string {
+	var ids []string
+	for pages := 0; pages < 20; pages++ {
+		page, err := gateway.GetAll(filter)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Orders), filter.Limit)
+		ids = append(ids, orderIDs(page)...)
+		if page.NextCursor == "" {
+			return ids
+		}
+		filter.Cursor = page.NextCursor
+	}
+	t.Fatalf("listing %+v did not end after 20 pages", filter)
+	return nil
+}
+
+func testInvalidCursor(t *testing.T, gateway interfaces.OrderGatewayI) {
+	_, err := gateway.GetAll(entities.OrderFilter{Limit: 10, Cursor: "not a cursor"})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	if assert.True(t, ok, "expected a bad request error, got %v", err) {
+		assert.Equal(t, util.BadRequestCategory, domainErr.Category)
+	}
+}
+
+func testSoftDeletion(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("kept", "c1", 0))
+	save(t, gateway, NewOrder("deleted", "c1", 1))
+
+	order, err := gateway.GetByID("deleted")
+	require.NoError(t, err)
+	deletedAt := baseTime.Add(time.Hour)
+	order.DeletedAt = &deletedAt
+	order.DeletedBy = "admin"
+	order.UpdatedAt = deletedAt
+	_, err = gateway.Update("deleted", order, entities.OrderDeletionField)
+	require.NoError(t, err)
+
+	fetched, err := gateway.GetByID("deleted")
+	require.NoError(t, err)
+	require.NotNil(t, fetched)
+	assert.True(t, fetched.IsDeleted())
+	assert.Equal(t, "admin", fetched.DeletedBy)
+
+	for _, filter := range []entities.OrderFilter{{Limit: 10}, {ClientID: "c1", Limit: 10}} {
+		page, err := gateway.GetAll(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept"}, orderIDs(page))
+
+		filter.IncludeDeleted = true
+		page, err = gateway.GetAll(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept", "deleted"}, orderIDs(page))
+	}
+
+	fetched.DeletedAt = nil
+	fetched.DeletedBy = ""
+	restored, err := gateway.Update("deleted", fetched, entities.OrderDeletionField)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+
+	page, err := gateway.GetAll(entities.OrderFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept", "deleted"}, orderIDs(page))
+}
+
+// testConcurrentUpdates updates the same version of an order from many goroutines: exactly one update wins
+func testConcurrentUpdates(t *testing.T, gateway interfaces.OrderGatewayI) {
+	save(t, gateway, NewOrder("1", "c1", 0))
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		order, err := gateway.GetByID("1")
+		require.NoError(t, err)
+		order.Notes = fmt.Sprintf("writer %d", i)
+
+		wg.Add(1)
+		go func(i int, order *entities.Order) {
+			defer wg.Done()
+			_, errs[i] = gateway.Update("1", order, entities.OrderNotesField)
+		}(i, order)
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		if err == nil {
+			assert.Equal(t, -1, winner, "more than one update won")
+			winner = i
+			continue
+		}
+		assertConflict(t, err)
+	}
+	require.NotEqual(t, -1, winner, "no update won")
+
+	fetched, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched.Version)
+	assert.Equal(t, fmt.Sprintf("writer %d", winner), fetched.Notes)
+}
+
+// testConcurrentCreations saves new orders with the same ID from many goroutines: exactly one is created
+func testConcurrentCreations(t *testing.T, gateway interfaces.OrderGatewayI) {
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = gateway.Save(NewOrder("1", fmt.Sprintf("c%d", i), 0))
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assertConflict(t, err)
+	}
+	assert.Equal(t, 1, created)
+
+	fetched, err := gateway.GetByID("1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetched.Version)
+}
@@ -16,7 +16,7 @@ Feature: API Pedido
 
     Examples:
       | statusCode | status |
-      | 200       | CRIADO |
+      | 200       | APROVADO |
       | 422       | ERRO   |
 
   Scenario Outline: Pedido patch
@@ -26,7 +26,7 @@ Feature: API Pedido
 
     Examples:
       | statusCode | status |
-      | 200       | CRIADO |
+      | 200       | RECEBIDO |
       | 422       | ERRO   |
 
   Scenario Outline: Pedido GET - Success
@@ -66,7 +66,7 @@ Feature: API Pedido
     Examples:
       | status | statusCode |
       | CRIADO | 200       |
-      | ERRO | 200       |
+      | ERRO | 400       |
 
   Scenario Outline: Should get healthcheck
     When request GET /healthcheck
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	idg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/idempotency"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/idempotency"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyStart_ReservesNewKey(t *testing.T) {
+	gateway := new(mocks.IdempotencyGateway)
+	gateway.On("Create", mock.Anything, mock.Anything).Return(true, nil)
+	useCase := idempotency.NewUseCase(gateway)
+
+	record, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "client-1#key-1", record.Key)
+	assert.False(t, record.IsCompleted())
+	assert.Greater(t, record.ExpiresAt, record.CreatedAt.Unix())
+}
+
+func TestIdempotencyStart_Replay(t *testing.T) {
+	first := new(mocks.IdempotencyGateway)
+	first.On("Create", mock.Anything, mock.Anything).Return(true, nil)
+	reserved, _ := idempotency.NewUseCase(first).Start("client-1", "key-1", []byte(`{}`))
+	reserved.StatusCode = http.StatusCreated
+
+	gateway := new(mocks.IdempotencyGateway)
+	gateway.On("Create", mock.Anything, mock.Anything).Return(false, nil)
+	gateway.On("GetByKey", "client-1#key-1").Return(reserved, nil)
+	useCase := idempotency.NewUseCase(gateway)
+
+	record, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, reserved, record)
+
+	_, err = useCase.Start("client-1", "key-1", []byte(`{"notes": "other"}`))
+	assert.True(t, util.IsDomainError(err))
+	assert.False(t, util.IsConflictError(err))
+
+	reserved.StatusCode = 0
+	_, err = useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.True(t, util.IsConflictError(err))
+}
+
+func TestIdempotencyStart_TakesOverAbandonedKey(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	gateway := idg.NewMemoryGateway()
+	useCase := idempotency.NewUseCase(gateway)
+	useCase.Clock = func() time.Time { return now }
+
+	first, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+
+	// The first request is still within its lock
+	now = now.Add(idempotency.DefaultLockTimeout - time.Second)
+	_, err = useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.True(t, util.IsConflictError(err))
+
+	// The first request died without completing or releasing the key
+	now = now.Add(2 * time.Second)
+	retry, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.False(t, retry.IsCompleted())
+	assert.Greater(t, retry.LockedUntil, first.LockedUntil)
+
+	// The retry now holds the key
+	_, err = useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.True(t, util.IsConflictError(err))
+	assert.NoError(t, useCase.Complete(retry, http.StatusCreated, "application/json", []byte(`{"order_id":"1"}`)))
+	replay, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"order_id":"1"}`, replay.Response)
+}
+
+func TestIdempotencyComplete_RequestThatLostItsKeyDoesNotOverwriteTheRetry(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	gateway := idg.NewMemoryGateway()
+	useCase := idempotency.NewUseCase(gateway)
+	useCase.Clock = func() time.Time { return now }
+
+	first, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	now = now.Add(idempotency.DefaultLockTimeout + time.Second)
+	retry, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+
+	// The first request outlived its lock, it neither completes nor releases the key of the retry
+	err = useCase.Complete(first, http.StatusCreated, "application/json", []byte(`{"order_id":"1"}`))
+	assert.True(t, util.IsConflictError(err))
+	assert.True(t, util.IsConflictError(useCase.Release(first)))
+	stored, _ := gateway.GetByKey(retry.Key)
+	assert.False(t, stored.IsCompleted())
+	assert.Equal(t, retry.LockedUntil, stored.LockedUntil)
+
+	assert.NoError(t, useCase.Complete(retry, http.StatusCreated, "application/json", []byte(`{"order_id":"2"}`)))
+	replay, err := useCase.Start("client-1", "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"order_id":"2"}`, replay.Response)
+}
+
+func TestPOST_IdempotentReplay(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	idempotencyUseCase := new(mocks.IdempotencyUseCase)
+	idempotencyUseCase.On("Start", "client-1", "key-1", mock.Anything).
+		Return(&entities.IdempotencyRecord{StatusCode: http.StatusCreated, Response: `{"order_id":"1"}`}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "client-1"}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, idempotencyUseCase, c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, `{"order_id":"1"}`, res.Body.String())
+	assert.Equal(t, "true", res.Header().Get("Idempotent-Replayed"))
+	useCase.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPOST_IdempotentReplayOfProblem(t *testing.T) {
+	idempotencyUseCase := new(mocks.IdempotencyUseCase)
+	idempotencyUseCase.On("Start", "client-1", "key-1", mock.Anything).Return(&entities.IdempotencyRecord{
+		StatusCode:  http.StatusUnprocessableEntity,
+		ContentType: "application/problem+json",
+		Response:    `{"status":422}`,
+	}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "client-1"}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(new(mocks.OrderUseCase), idempotencyUseCase, c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+	assert.Equal(t, `{"status":422}`, res.Body.String())
+}
+
+func TestPOST_IdempotentFirstRequestIsStored(t *testing.T) {
+	record := &entities.IdempotencyRecord{Key: "client-1#key-1"}
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Create", mock.Anything).Return(&entities.Order{OrderID: "1", ClientID: "client-1"}, nil)
+	idempotencyUseCase := new(mocks.IdempotencyUseCase)
+	idempotencyUseCase.On("Start", "client-1", "key-1", mock.Anything).Return(record, nil)
+	idempotencyUseCase.On("Complete", record, http.StatusCreated, mock.Anything, mock.Anything).Return(nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "client-1"}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, idempotencyUseCase, c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	idempotencyUseCase.AssertExpectations(t)
+	stored := idempotencyUseCase.Calls[1].Arguments.Get(3).([]byte)
+	assert.Equal(t, res.Body.String(), string(stored))
+}
+
+func TestPOST_IdempotentFailureIsReleased(t *testing.T) {
+	record := &entities.IdempotencyRecord{Key: "client-1#key-1"}
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Create", mock.Anything).Return(nil, errUsecaseFailure)
+	idempotencyUseCase := new(mocks.IdempotencyUseCase)
+	idempotencyUseCase.On("Start", "client-1", "key-1", mock.Anything).Return(record, nil)
+	idempotencyUseCase.On("Release", record).Return(nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "client-1"}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, idempotencyUseCase, c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	idempotencyUseCase.AssertExpectations(t)
+	idempotencyUseCase.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPOST_IdempotencyKeyReusedWithOtherBody(t *testing.T) {
+	idempotencyUseCase := new(mocks.IdempotencyUseCase)
+	idempotencyUseCase.On("Start", "client-1", "key-1", mock.Anything).
+		Return(nil, util.NewErrorDomain("already used with a different request"))
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "client-1"}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(new(mocks.OrderUseCase), idempotencyUseCase, c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
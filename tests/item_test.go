@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/item"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateOrder_ResolvesCatalogItems(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("Save", mock.Anything).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").
+		Return(&entities.Item{ItemID: "1", Name: "X-Burger", Category: "LANCHE", Price: 25.5}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	newOrder := &entities.Order{
+		ClientID:     "123",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Name: "free burger", Price: 0.01, Quantity: 2}},
+	}
+	_, err := useCase.Create(newOrder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "X-Burger", newOrder.OrderedItems[0].Name)
+	assert.Equal(t, "LANCHE", newOrder.OrderedItems[0].Category)
+	assert.Equal(t, 25.5, newOrder.OrderedItems[0].Price)
+	assert.Equal(t, entities.Cents(5100), newOrder.Total)
+}
+
+func TestCreateOrder_UnknownOrDeletedItem(t *testing.T) {
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "unknown").Return(nil, nil)
+	itemGateway.On("GetByID", "deleted").Return(&entities.Item{ItemID: "deleted", DeletedAt: time.Now()}, nil)
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	for _, itemID := range []string{"unknown", "deleted"} {
+		_, err := useCase.Create(&entities.Order{
+			OrderedItems: []entities.OrderedItem{{ItemID: itemID, Quantity: 1}},
+		})
+
+		assert.True(t, util.IsDomainError(err), "Domain error is expected")
+	}
+	orderGateway.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestItemGetByID_NotFound(t *testing.T) {
+	useCase := new(mocks.ItemUseCase)
+	useCase.On("GetByID", "1").Return(nil, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/itens/1", nil)
+
+	c := chi.NewRouter()
+	controllers.NewItemController(useCase, c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestItemCreate_DomainError(t *testing.T) {
+	useCase := new(mocks.ItemUseCase)
+	useCase.On("Create", mock.Anything).Return(nil, util.NewErrorDomain("Item name is required"))
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/itens", strings.NewReader(`{"price": 10}`))
+
+	c := chi.NewRouter()
+	controllers.NewItemController(useCase, c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
+
+func TestItemCreate_ReportsEveryInvalidField(t *testing.T) {
+	itemGateway := new(mocks.ItemGateway)
+	useCase := item.NewUseCase(itemGateway)
+
+	_, err := useCase.Create(&entities.Item{Price: -1})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "invalid_item", domainErr.Code)
+		assert.Equal(t, []util.FieldError{
+			{Field: "name", Message: "must not be empty"},
+			{Field: "price", Message: "must be greater than zero"},
+		}, domainErr.Fields)
+	}
+	itemGateway.AssertNotCalled(t, "Save", mock.Anything)
+}
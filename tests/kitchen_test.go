@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/adapters/kitchen"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var kitchenNow = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func receivedOrder(orderID string, status entities.Status, receivedAt time.Time, items ...entities.OrderedItem) entities.Order {
+	return entities.Order{
+		OrderID:      orderID,
+		Status:       status,
+		OrderedItems: items,
+		CreatedAt:    receivedAt.Add(-time.Hour),
+		StatusHistory: []entities.StatusChange{
+			{From: entities.ApprovedPaymentOrderStatus, To: entities.ReceivedOrderStatus, ChangedAt: receivedAt},
+		},
+	}
+}
+
+func TestKitchenQueue_OrderAndItems(t *testing.T) {
+	burger := entities.OrderedItem{ItemID: "1", Name: "X-Burger", Quantity: 2}
+	fries := entities.OrderedItem{ItemID: "2", Name: "Fritas", Quantity: 1}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetAll", entities.OrderFilter{
+		Statuses: []entities.Status{entities.CookingOrderStatus, entities.ReceivedOrderStatus},
+		Limit:    100,
+	}).Return(&entities.OrderPage{
+		Orders: []entities.Order{
+			receivedOrder("late", entities.ReceivedOrderStatus, kitchenNow.Add(-5*time.Minute), burger),
+			receivedOrder("early", entities.ReceivedOrderStatus, kitchenNow.Add(-20*time.Minute), burger, fries),
+		},
+		NextCursor: "next",
+	}, nil)
+	orderGateway.On("GetAll", entities.OrderFilter{
+		Statuses: []entities.Status{entities.CookingOrderStatus, entities.ReceivedOrderStatus},
+		Limit:    100,
+		Cursor:   "next",
+	}).Return(&entities.OrderPage{
+		Orders: []entities.Order{
+			receivedOrder("cooking", entities.CookingOrderStatus, kitchenNow.Add(-2*time.Minute), fries, burger, burger),
+		},
+	}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+	useCase.Clock = func() time.Time { return kitchenNow }
+
+	queue, err := useCase.KitchenQueue()
+
+	assert.NoError(t, err)
+	var orderIDs []string
+	for _, queued := range queue.Orders {
+		orderIDs = append(orderIDs, queued.Order.OrderID)
+	}
+	assert.Equal(t, []string{"cooking", "early", "late"}, orderIDs)
+	assert.Equal(t, 20*time.Minute, queue.Orders[1].Elapsed)
+	assert.Equal(t, []entities.KitchenItem{
+		{ItemID: "1", Name: "X-Burger", Quantity: 8, Orders: 3},
+		{ItemID: "2", Name: "Fritas", Quantity: 2, Orders: 2},
+	}, queue.Items)
+}
+
+func TestClaim_ReceivedOrderStartsPreparation(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus, Version: 2}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, []entities.OrderField{entities.OrderClaimField, entities.OrderStatusField}).
+		Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+	useCase.Clock = func() time.Time { return kitchenNow }
+
+	orderClaimed, err := useCase.Claim("1", "chapa", "cook-1", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "chapa", orderClaimed.Station)
+	assert.Equal(t, kitchenNow, *orderClaimed.ClaimedAt)
+	assert.Equal(t, entities.CookingOrderStatus, orderClaimed.Status)
+	assert.Equal(t, []entities.EventType{entities.OrderClaimedEvent, entities.OrderStatusChangedEvent}, eventTypes(existing))
+}
+
+func TestClaim_SameStationIsNoop(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CookingOrderStatus, Station: "chapa"}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderClaimed, err := useCase.Claim("1", "chapa", "", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, orderClaimed)
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaim_ClaimedByAnotherStation(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CookingOrderStatus, Station: "chapa"}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Claim("1", "fritadeira", "", 0)
+
+	assert.True(t, util.IsConflictError(err))
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaim_OrderNotQueued(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReadyOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Claim("1", "chapa", "", 0)
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, util.InvalidTransitionCategory, domainErr.Category)
+}
+
+func TestClaim_RequiresStation(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Claim("1", " ", "", 0)
+
+	assert.True(t, util.IsDomainError(err))
+	orderGateway.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func serveKitchen(useCase *mocks.OrderUseCase, req *http.Request) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	c := chi.NewRouter()
+	controllers.NewKitchenController(useCase, c)
+	c.ServeHTTP(res, req)
+	return res
+}
+
+func TestGETKitchenQueue(t *testing.T) {
+	queued := receivedOrder("1", entities.ReceivedOrderStatus, kitchenNow.Add(-90*time.Second),
+		entities.OrderedItem{ItemID: "1", Name: "X-Burger", Quantity: 12})
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("KitchenQueue").Return(&entities.KitchenQueue{
+		Orders: []entities.KitchenOrder{{Order: queued, ReceivedAt: kitchenNow.Add(-90 * time.Second), Elapsed: 90 * time.Second}},
+		Items:  []entities.KitchenItem{{ItemID: "1", Name: "X-Burger", Quantity: 12, Orders: 1}},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/cozinha/fila", nil)
+	res := serveKitchen(useCase, req)
+
+	var queue kitchen.Queue
+	json.NewDecoder(res.Body).Decode(&queue)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, int64(90), queue.Orders[0].ElapsedSeconds)
+	assert.Equal(t, []kitchen.ItemTotal{{ItemID: "1", Name: "X-Burger", Quantity: 12, Orders: 1}}, queue.Items)
+}
+
+func TestPOSTClaim_Success(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Claim", "1", "chapa", "cook-1", 3).
+		Return(&entities.Order{OrderID: "1", Status: entities.CookingOrderStatus, Station: "chapa", Version: 4}, nil)
+
+	req, _ := http.NewRequest("POST", "/cozinha/fila/1/claim", strings.NewReader(`{"station": "chapa"}`))
+	req.Header.Set("X-Actor", "cook-1")
+	req.Header.Set("If-Match", `"3"`)
+	res := serveKitchen(useCase, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `"4"`, res.Header().Get("ETag"))
+	assert.Contains(t, res.Body.String(), `"station":"chapa"`)
+}
+
+func TestPOSTClaim_AlreadyClaimed(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Claim", "1", "fritadeira", "", 0).
+		Return(nil, util.NewConflictError("order_already_claimed", "Order 1 was already claimed by station chapa"))
+
+	req, _ := http.NewRequest("POST", "/cozinha/fila/1/claim", strings.NewReader(`{"station": "fritadeira"}`))
+	res := serveKitchen(useCase, req)
+
+	assert.Equal(t, http.StatusConflict, res.Code)
+}
+
+func TestPOSTClaim_NotFound(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Claim", "1", "chapa", "", 0).Return(nil, nil)
+
+	req, _ := http.NewRequest("POST", "/cozinha/fila/1/claim", strings.NewReader(`{"station": "chapa"}`))
+	res := serveKitchen(useCase, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
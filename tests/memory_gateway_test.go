@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	ig "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/item"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/gateways/message"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func memoryOrder(orderID, clientID string, createdAt time.Time) *entities.Order {
+	return &entities.Order{
+		OrderID:      orderID,
+		ClientID:     clientID,
+		Status:       entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1, Price: 10}},
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+}
+
+func TestMemoryGateway_NotFoundIsNil(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+
+	fetched, err := gateway.GetByID("missing")
+	assert.NoError(t, err)
+	assert.Nil(t, fetched)
+
+	updated, err := gateway.Update("missing", memoryOrder("missing", "c1", time.Now()), entities.OrderNotesField)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+}
+
+func TestMemoryGateway_WritesAreConditionedOnVersion(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+	_, err := gateway.Save(memoryOrder("1", "c1", time.Now()))
+	assert.NoError(t, err)
+
+	_, err = gateway.Save(memoryOrder("1", "c1", time.Now()))
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, util.ConflictCategory, domainErr.Category)
+
+	stale, _ := gateway.GetByID("1")
+	current, _ := gateway.GetByID("1")
+	current.Notes = "first"
+	_, err = gateway.Update("1", current, entities.OrderNotesField)
+	assert.NoError(t, err)
+
+	stale.Notes = "second"
+	_, err = gateway.Update("1", stale, entities.OrderNotesField)
+	domainErr, ok = util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, util.ConflictCategory, domainErr.Category)
+}
+
+func TestMemoryGateway_UpdatesOnlyTheGivenFields(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+	saved, _ := gateway.Save(memoryOrder("1", "c1", time.Now()))
+
+	saved.Notes = "sem cebola"
+	saved.Status = entities.CanceledOrderStatus
+	updated, err := gateway.Update("1", saved, entities.OrderNotesField)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sem cebola", updated.Notes)
+	assert.Equal(t, entities.CreatedOrdersStatus, updated.Status)
+	assert.Equal(t, 2, updated.Version)
+}
+
+func TestMemoryGateway_ReturnsCopies(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+	saved, _ := gateway.Save(memoryOrder("1", "c1", time.Now()))
+	saved.OrderedItems[0].Quantity = 99
+
+	fetched, _ := gateway.GetByID("1")
+	fetched.OrderedItems[0].Quantity = 42
+
+	again, _ := gateway.GetByID("1")
+	assert.Equal(t, 1, again.OrderedItems[0].Quantity)
+}
+
+func TestMemoryGateway_PagesThroughClientOrders(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	for i, orderID := range []string{"a", "b", "c", "d"} {
+		_, err := gateway.Save(memoryOrder(orderID, "c1", start.Add(time.Duration(i)*time.Minute)))
+		assert.NoError(t, err)
+	}
+	_, _ = gateway.Save(memoryOrder("other", "c2", start))
+
+	filter := entities.OrderFilter{ClientID: "c1", Descending: true, Limit: 3}
+	var orderIDs []string
+	for {
+		page, err := gateway.GetAll(filter)
+		assert.NoError(t, err)
+		for _, o := range page.Orders {
+			orderIDs = append(orderIDs, o.OrderID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"d", "c", "b", "a"}, orderIDs)
+}
+
+func TestMemoryGateways_PublishCreatedOrderThroughOutbox(t *testing.T) {
+	outboxGateway := obg.NewMemoryGateway()
+	queueGateway := message.NewMemoryGateway()
+	itemGateway := ig.NewMemoryGateway()
+	_, _ = itemGateway.Save(&entities.Item{ItemID: "1", Name: "X-Burguer", Price: 20})
+	useCase := order.NewUseCase(og.NewMemoryGateway(outboxGateway), itemGateway)
+
+	created, err := useCase.Create(memoryOrder("", "c1", time.Time{}))
+	assert.NoError(t, err)
+	assert.NoError(t, outbox.NewRelay(outboxGateway, queueGateway).PublishPending())
+
+	messages := queueGateway.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, entities.OrderCreatedEvent, messages[0].Type)
+		assert.Equal(t, created.OrderID, messages[0].Data.Order.OrderID)
+	}
+	pending, _ := outboxGateway.GetPending(time.Now(), 10)
+	assert.Empty(t, pending)
+}
+
+func TestQueueGatewayWithoutSQS_RecordsMessages(t *testing.T) {
+	queueGateway := message.NewGateway(nil, "")
+
+	assert.NoError(t, queueGateway.Publish(&entities.OrderEvent{EventID: "e1", Type: entities.OrderCreatedEvent}))
+
+	recorded, ok := queueGateway.(*message.MemoryGateway)
+	if assert.True(t, ok, "local runs without SQS record the messages instead of dropping them") {
+		assert.Equal(t, "e1", recorded.Messages()[0].EventID)
+	}
+}
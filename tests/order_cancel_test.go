@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCancel_BeforePreparation(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderCancelled, err := useCase.Cancel("1", "client-1", "changed my mind", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.CanceledOrderStatus, orderCancelled.Status)
+	change := orderCancelled.StatusHistory[len(orderCancelled.StatusHistory)-1]
+	assert.Equal(t, "changed my mind", change.Reason)
+	assert.Equal(t, "client-1", change.Actor)
+	assert.Equal(t, []entities.EventType{entities.OrderCancelledEvent}, eventTypes(existing))
+}
+
+func TestCancel_AfterPreparationStarted(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CookingOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Cancel("1", "", "too late", 0)
+
+	assert.True(t, util.IsDomainError(err))
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancel_RequiresReason(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Cancel("1", "", " ", 0)
+
+	assert.True(t, util.IsDomainError(err))
+	orderGateway.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestCancel_AlreadyCancelledIsNoop(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CanceledOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderCancelled, err := useCase.Cancel("1", "", "again", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, orderCancelled)
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPOSTCancel_Success(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Cancel", "1", "client-1", "changed my mind", 3).
+		Return(&entities.Order{OrderID: "1", Status: entities.CanceledOrderStatus, Version: 4}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos/1/cancel", strings.NewReader(`{"reason": "changed my mind"}`))
+	req.Header.Set("X-Actor", "client-1")
+	req.Header.Set("If-Match", `"3"`)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `"4"`, res.Header().Get("ETag"))
+	assert.Contains(t, res.Body.String(), `"status":"CANCELADO"`)
+}
+
+func TestPOSTCancel_NotAllowed(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Cancel", "1", "", "too late", 0).
+		Return(nil, util.NewInvalidTransitionError("cancellation_not_allowed", "can no longer be cancelled"))
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos/1/cancel", strings.NewReader(`{"reason": "too late"}`))
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
+
+func TestUpdate_CancellationRequiresReason(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}}}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Update("1", &entities.Order{
+		Status:       entities.CanceledOrderStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}, "", "")
+
+	domainErr, ok := util.AsErrorDomain(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, util.ValidationCategory, domainErr.Category)
+		assert.Equal(t, "reason", domainErr.Fields[0].Field)
+	}
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdate_CancelsWithReason(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}}}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, []entities.OrderField{entities.OrderStatusField}).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderCancelled, err := useCase.Update("1", &entities.Order{
+		Status:       entities.CanceledOrderStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}, "client-1", "changed my mind")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.CanceledOrderStatus, orderCancelled.Status)
+	assert.Equal(t, "changed my mind", orderCancelled.StatusHistory[len(orderCancelled.StatusHistory)-1].Reason)
+	assert.Equal(t, []entities.EventType{entities.OrderCancelledEvent}, eventTypes(existing))
+}
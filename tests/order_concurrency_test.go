@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateOrderStatus_OutdatedVersion(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").
+		Return(&entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus, Version: 4}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.UpdateOrderStatus("1", entities.CookingOrderStatus, "", "", 3)
+
+	assert.True(t, util.IsConflictError(err), "Conflict error is expected")
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPATCH_IfMatchConflict(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("UpdateOrderStatus", "1", entities.Status("PRONTO"), "", "", 3).
+		Return(nil, util.NewConflictError("version_mismatch", "Order 1 is at version 4, not 3"))
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(`{"status":"PRONTO"}`))
+	req.Header.Set("If-Match", `"3"`)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusConflict, res.Code)
+}
+
+func TestPUT_InvalidIfMatch(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/pedidos/1", strings.NewReader(`{}`))
+	req.Header.Set("If-Match", `"abc"`)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestGetByID_ETag(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(&entities.Order{OrderID: "1", Version: 7}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos/1", nil)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `"7"`, res.Header().Get("ETag"))
+}
@@ -27,13 +27,13 @@ var (
 
 func TestGetAll_Error(t *testing.T) {
 	useCase := new(mocks.OrderUseCase)
-	useCase.On("List", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, errUsecaseFailure)
+	useCase.On("List", mock.AnythingOfType("entities.OrderFilter")).Return(nil, errUsecaseFailure)
 
 	res := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/pedidos", nil)
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -49,7 +49,7 @@ func TestGetByID_Error(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/pedidos/1", nil)
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -65,7 +65,7 @@ func TestCreate_ErrorParse(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(badJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -81,7 +81,7 @@ func TestCreate_ErrorUsecase(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(okJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -97,7 +97,7 @@ func TestPUT_ErrorParse(t *testing.T) {
 	req, _ := http.NewRequest("PUT", "/pedidos/1", strings.NewReader(badJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -106,14 +106,14 @@ func TestPUT_ErrorParse(t *testing.T) {
 
 func TestPUT_ErrorUsecase(t *testing.T) {
 	useCase := new(mocks.OrderUseCase)
-	useCase.On("Update", mock.Anything, mock.Anything).Return(nil, errUsecaseFailure)
+	useCase.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errUsecaseFailure)
 
 	res := httptest.NewRecorder()
 	okJSON := `{}`
 	req, _ := http.NewRequest("PUT", "/pedidos/1", strings.NewReader(okJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -129,7 +129,7 @@ func TestPATCH_ErrorParse(t *testing.T) {
 	req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(badJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -138,14 +138,14 @@ func TestPATCH_ErrorParse(t *testing.T) {
 
 func TestPATCH_ErrorUsecase(t *testing.T) {
 	useCase := new(mocks.OrderUseCase)
-	useCase.On("UpdateOrderStatus", mock.Anything, mock.Anything).Return(nil, errUsecaseFailure)
+	useCase.On("UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errUsecaseFailure)
 
 	res := httptest.NewRecorder()
 	okJSON := `{}`
 	req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(okJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -170,8 +170,8 @@ func TestGET_Success(t *testing.T) {
 		Status:       "CRIADO",
 		OrderedItems: orderedItems,
 		Notes:        "nota",
-		CreatedAt:    time.Now().String(),
-		UpdatedAt:    time.Now().String(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	useCase := new(mocks.OrderUseCase)
@@ -182,7 +182,7 @@ func TestGET_Success(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/pedidos/1", strings.NewReader(okJSON))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -210,8 +210,8 @@ func TestPOST_Success(t *testing.T) {
 		Status:       "CRIADO",
 		OrderedItems: orderedItems,
 		Notes:        "nota",
-		CreatedAt:    time.Now().String(),
-		UpdatedAt:    time.Now().String(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	ordItemEntity = append(ordItemEntity, entities.OrderedItem{
@@ -228,8 +228,8 @@ func TestPOST_Success(t *testing.T) {
 		Status:       "CRIADO",
 		OrderedItems: ordItemEntity,
 		Notes:        "nota",
-		CreatedAt:    time.Now().String(),
-		UpdatedAt:    time.Now().String(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 	body, _ := json.Marshal(newOrder)
 
@@ -240,7 +240,7 @@ func TestPOST_Success(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/pedidos", bytes.NewBuffer(body))
 
 	c := chi.NewRouter()
-	controllers.NewOrderController(useCase, c)
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
 
 	c.ServeHTTP(res, req)
 
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func eventTypes(o *entities.Order) (types []entities.EventType) {
+	for _, event := range o.PendingEvents {
+		types = append(types, event.Event.Type)
+	}
+	return types
+}
+
+func TestUpdate_EmitsUpdatedAndStatusChangedEvents(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus, Version: 2}
+	var saved *entities.Order
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entities.Order)
+	}).Return(existing, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	_, err := useCase.Update("1", &entities.Order{
+		Status:       entities.ApprovedPaymentOrderStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}, "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.EventType{entities.OrderUpdatedEvent, entities.OrderStatusChangedEvent}, eventTypes(saved))
+	event := saved.PendingEvents[1].Event
+	assert.Equal(t, entities.OrderEventSchemaVersion, event.SchemaVersion)
+	assert.Equal(t, "1", event.CorrelationID)
+	assert.Equal(t, 3, event.Data.Order.Version)
+	assert.Equal(t, entities.ApprovedPaymentOrderStatus, event.Data.StatusChange.To)
+}
+
+func TestUpdateOrderStatus_EmitsCancelledEvent(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.UpdateOrderStatus("1", entities.CanceledOrderStatus, "", "", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.EventType{entities.OrderCancelledEvent}, eventTypes(existing))
+}
+
+func TestUpdateOrderStatus_SameStatusIsNoop(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ApprovedPaymentOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderFetched, err := useCase.UpdateOrderStatus("1", entities.ApprovedPaymentOrderStatus, "", "", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, orderFetched)
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_EmitsDeletedEvent(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	err := useCase.Delete("1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.EventType{entities.OrderDeletedEvent}, eventTypes(existing))
+}
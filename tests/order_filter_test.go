@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAll_Filters(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("List", entities.OrderFilter{
+		ClientID:    "123",
+		Statuses:    []entities.Status{entities.ReadyOrderStatus, entities.DeliveredOrderStatus},
+		CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+		ItemID:      "7",
+		Descending:  true,
+	}).Return(&entities.OrderPage{}, nil)
+
+	req, _ := http.NewRequest("GET", "/pedidos?client_id=123&status=PRONTO,ENTREGUE&item_id=7&sort=-created_at"+
+		"&created_from=2024-01-01T00:00:00Z&created_to=2024-01-31T23:59:59Z", nil)
+	res, _ := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	useCase.AssertExpectations(t)
+}
+
+func TestGetAll_RepeatedStatus(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("List", entities.OrderFilter{
+		Statuses: []entities.Status{entities.ReadyOrderStatus, entities.DeliveredOrderStatus},
+	}).Return(&entities.OrderPage{}, nil)
+
+	req, _ := http.NewRequest("GET", "/pedidos?status=PRONTO&status=ENTREGUE", nil)
+	res, _ := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	useCase.AssertExpectations(t)
+}
+
+func TestGetAll_InvalidFilters(t *testing.T) {
+	for query, field := range map[string]string{
+		"created_from=yesterday":     "created_from",
+		"created_to=2024-01-31":      "created_to",
+		"sort=status":                "sort",
+		"include_deleted=sometimes":  "include_deleted",
+		"limit=ten&sort=-created_at": "limit",
+	} {
+		useCase := new(mocks.OrderUseCase)
+
+		req, _ := http.NewRequest("GET", "/pedidos?"+query, nil)
+		res, problem := serveOrders(useCase, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code, query)
+		assert.Equal(t, "invalid_parameter", problem.Code, query)
+		assert.Equal(t, []util.FieldError{{Field: field, Message: problem.Errors[0].Message}}, problem.Errors, query)
+		useCase.AssertNotCalled(t, "List", mock.Anything)
+	}
+}
+
+func TestList_DeduplicatesStatuses(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetAll", entities.OrderFilter{
+		Statuses: []entities.Status{entities.ReadyOrderStatus, entities.DeliveredOrderStatus},
+		Limit:    50,
+	}).Return(&entities.OrderPage{}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.List(entities.OrderFilter{
+		Statuses: []entities.Status{entities.ReadyOrderStatus, entities.DeliveredOrderStatus, entities.ReadyOrderStatus},
+	})
+
+	assert.NoError(t, err)
+	orderGateway.AssertExpectations(t)
+}
+
+func TestList_InvalidStatus(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.List(entities.OrderFilter{Statuses: []entities.Status{"PERDIDO"}})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, util.BadRequestCategory, domainErr.Category)
+	assert.Equal(t, "status", domainErr.Fields[0].Field)
+	orderGateway.AssertNotCalled(t, "GetAll", mock.Anything)
+}
+
+func TestList_InvertedDateRange(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.List(entities.OrderFilter{
+		CreatedFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, "created_to", domainErr.Fields[0].Field)
+	orderGateway.AssertNotCalled(t, "GetAll", mock.Anything)
+}
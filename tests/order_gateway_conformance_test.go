@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
+	"github.com/postech-soat2-grupo16/pedidos-api/external"
+
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/tests/conformance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryOrderGateway_Conformance(t *testing.T) {
+	conformance.RunOrderGatewaySuite(t, func(t *testing.T) interfaces.OrderGatewayI {
+		return og.NewMemoryGateway(obg.NewMemoryGateway())
+	})
+}
+
+// TestPostgresOrderGateway_Conformance runs against the database of POSTGRES_TEST_DSN, whose order tables are emptied
+func TestPostgresOrderGateway_Conformance(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, og.MigratePostgres(db))
+
+	conformance.RunOrderGatewaySuite(t, func(t *testing.T) interfaces.OrderGatewayI {
+		_, err := db.Exec("TRUNCATE orders, ordered_items, orders_outbox")
+		require.NoError(t, err)
+		return og.NewPostgresGateway(db)
+	})
+}
+
+// TestDynamoDBOrderGateway_Conformance runs against the DynamoDB Local of DYNAMODB_TEST_ENDPOINT,
+// on new tables for every test that are deleted afterwards
+func TestDynamoDBOrderGateway_Conformance(t *testing.T) {
+	svc := dynamoDBTestClient(t)
+
+	suffix := time.Now().UnixNano()
+	tables := 0
+	conformance.RunOrderGatewaySuite(t, func(t *testing.T) interfaces.OrderGatewayI {
+		tables++
+		tableName := fmt.Sprintf("orders_%d_%d", suffix, tables)
+		outboxTableName := fmt.Sprintf("orders_outbox_%d_%d", suffix, tables)
+		external.CreateOrderTables(svc, tableName, outboxTableName)
+		t.Cleanup(func() {
+			for _, table := range []string{tableName, outboxTableName} {
+				svc.DeleteTable(&dynamodb.DeleteTableInput{TableName: aws.String(table)})
+			}
+		})
+		return og.NewGateway(svc, tableName, outboxTableName)
+	})
+}
+
+// dynamoDBTestClient connects to the DynamoDB Local of DYNAMODB_TEST_ENDPOINT, skipping the test when it is not set
+func dynamoDBTestClient(t *testing.T) *dynamodb.DynamoDB {
+	endpoint := os.Getenv("DYNAMODB_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_TEST_ENDPOINT is not set")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(config.Default().AWS.Region),
+		Endpoint:    aws.String(endpoint),
+		Credentials: credentials.NewStaticCredentials("local", "local", ""),
+	})
+	require.NoError(t, err)
+	return dynamodb.New(sess)
+}
+
+// TestDynamoDBOrderGateway_EnsureIndexes creates the listing indexes on an orders table that only has the baseline ClientIdIndex
+func TestDynamoDBOrderGateway_EnsureIndexes(t *testing.T) {
+	svc := dynamoDBTestClient(t)
+
+	tableName := fmt.Sprintf("orders_baseline_%d", time.Now().UnixNano())
+	gateway := og.NewGateway(svc, tableName, config.Default().DynamoDB.Tables.OrdersOutbox)
+	_, err := svc.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String("order_id"), AttributeType: aws.String("S")},
+			{AttributeName: aws.String("client_id"), AttributeType: aws.String("S")},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("order_id"), KeyType: aws.String("HASH")}},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+			IndexName:             aws.String("ClientIdIndex"),
+			KeySchema:             []*dynamodb.KeySchemaElement{{AttributeName: aws.String("client_id"), KeyType: aws.String("HASH")}},
+			Projection:            &dynamodb.Projection{ProjectionType: aws.String("ALL")},
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(5)},
+		}},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(5)},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteTable(&dynamodb.DeleteTableInput{TableName: aws.String(tableName)}) })
+
+	created, err := gateway.EnsureIndexes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ClientIdCreatedAtIndex", "StatusCreatedAtIndex"}, created)
+
+	created, err = gateway.EnsureIndexes()
+	require.NoError(t, err)
+	assert.Empty(t, created)
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	og "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/order"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyOrderItem is an order as older versions of the API stored it, without item_ids
+func legacyOrderItem(orderID, createdAt string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"order_id":   {S: aws.String(orderID)},
+		"client_id":  {S: aws.String("c1")},
+		"status":     {S: aws.String("CRIADO")},
+		"created_at": {S: aws.String(createdAt)},
+		"updated_at": {S: aws.String(createdAt)},
+		"ordered_items": {L: []*dynamodb.AttributeValue{{M: map[string]*dynamodb.AttributeValue{
+			"item_id":  {S: aws.String("1")},
+			"quantity": {N: aws.String("2")},
+		}}}},
+	}
+}
+
+func TestOrderGateway_ReadsStoredTimestamps(t *testing.T) {
+	for name, tt := range map[string]struct {
+		stored   string
+		expected time.Time
+	}{
+		"fixed width": {
+			stored:   "2023-10-01T12:00:00.500000000Z",
+			expected: time.Date(2023, 10, 1, 12, 0, 0, 500000000, time.UTC),
+		},
+		"RFC 3339 with offset": {
+			stored:   "2023-10-01T09:00:00-03:00",
+			expected: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+		"legacy UTC": {
+			stored:   "2023-10-01 12:00:00.123456789 +0000 UTC",
+			expected: time.Date(2023, 10, 1, 12, 0, 0, 123456789, time.UTC),
+		},
+		"legacy with offset and monotonic clock": {
+			stored:   "2023-10-01 09:00:00.5 -0300 -03 m=+3.141592654",
+			expected: time.Date(2023, 10, 1, 12, 0, 0, 500000000, time.UTC),
+		},
+		"legacy without decimals": {
+			stored:   "2023-10-01 12:00:00 +0000 UTC",
+			expected: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+			require.NoError(t, gateway.Import([]map[string]*dynamodb.AttributeValue{legacyOrderItem("1", tt.stored)}))
+
+			order, err := gateway.GetByID("1")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, order.CreatedAt)
+			assert.Equal(t, time.UTC, order.CreatedAt.Location())
+		})
+	}
+}
+
+func TestOrderGateway_RejectsInvalidTimestamps(t *testing.T) {
+	for _, stored := range []string{"yesterday", "2023-10-01", "01/10/2023 12:00:00"} {
+		gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+		require.NoError(t, gateway.Import([]map[string]*dynamodb.AttributeValue{legacyOrderItem("1", stored)}))
+
+		_, err := gateway.GetByID("1")
+
+		assert.Error(t, err, stored)
+	}
+}
+
+func TestMemoryOrderGateway_Migrate(t *testing.T) {
+	gateway := og.NewMemoryGateway(obg.NewMemoryGateway())
+	current := legacyOrderItem("current", "2023-10-01T12:00:00.000000000Z")
+	current["item_ids"] = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{S: aws.String("1")}}}
+	invalid := legacyOrderItem("invalid", "yesterday")
+	invalid["client_id"] = &dynamodb.AttributeValue{S: aws.String("c2")}
+	require.NoError(t, gateway.Import([]map[string]*dynamodb.AttributeValue{
+		legacyOrderItem("legacy", "2023-10-01 09:00:00 -0300 -03"),
+		invalid,
+		current,
+	}))
+
+	result, err := gateway.Migrate()
+
+	require.NoError(t, err)
+	assert.Equal(t, og.MigrationResult{Scanned: 3, Migrated: 1, Failed: 1}, result)
+	migrated, err := gateway.GetByID("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), migrated.CreatedAt)
+	orders, err := gateway.GetAll(entities.OrderFilter{ClientID: "c1", ItemID: "1"})
+	require.NoError(t, err)
+	assert.Len(t, orders.Orders, 2, "the migrated order can be filtered by item")
+
+	// Running it again only retries the order it could not migrate
+	result, err = gateway.Migrate()
+
+	require.NoError(t, err)
+	assert.Equal(t, og.MigrationResult{Scanned: 3, Migrated: 0, Failed: 1}, result)
+}
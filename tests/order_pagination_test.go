@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAll_Pagination(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("List", entities.OrderFilter{Limit: 2, Cursor: "abc"}).Return(&entities.OrderPage{
+		Orders:     []entities.Order{{OrderID: "1"}, {OrderID: "2"}},
+		NextCursor: "def",
+	}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos?limit=2&cursor=abc", nil)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	var body struct {
+		Orders     []entities.Order `json:"orders"`
+		NextCursor string           `json:"next_cursor"`
+	}
+	json.NewDecoder(res.Body).Decode(&body)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Len(t, body.Orders, 2)
+	assert.Equal(t, "def", body.NextCursor)
+}
+
+func TestGetAll_InvalidLimit(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos?limit=ten", nil)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestList_ClampsLimit(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetAll", entities.OrderFilter{Limit: 100}).Return(&entities.OrderPage{}, nil)
+	orderGateway.On("GetAll", entities.OrderFilter{ClientID: "123", Limit: 50, Cursor: "abc"}).Return(&entities.OrderPage{}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.List(entities.OrderFilter{Limit: 1000})
+	assert.NoError(t, err)
+	_, err = useCase.List(entities.OrderFilter{ClientID: "123", Cursor: "abc"})
+	assert.NoError(t, err)
+	orderGateway.AssertExpectations(t)
+}
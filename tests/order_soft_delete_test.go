@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var yesterday = time.Now().Add(-24 * time.Hour)
+
+func TestDelete_MarksOrderDeleted(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, []entities.OrderField{entities.OrderDeletionField}).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	err := useCase.Delete("1", "admin")
+
+	assert.NoError(t, err)
+	assert.True(t, existing.IsDeleted())
+	assert.Equal(t, "admin", existing.DeletedBy)
+	orderGateway.AssertExpectations(t)
+}
+
+func TestGetByID_HidesDeletedOrders(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(&entities.Order{OrderID: "1", DeletedAt: &yesterday}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderFetched, err := useCase.GetByID("1")
+
+	assert.NoError(t, err)
+	assert.Nil(t, orderFetched)
+}
+
+func TestDelete_AlreadyDeleted(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(&entities.Order{OrderID: "1", DeletedAt: &yesterday}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	err := useCase.Delete("1", "admin")
+
+	assert.True(t, util.IsDomainError(err))
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestore_ClearsDeletion(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", DeletedAt: &yesterday, DeletedBy: "admin"}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, []entities.OrderField{entities.OrderDeletionField}).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderRestored, err := useCase.Restore("1", "admin")
+
+	assert.NoError(t, err)
+	assert.False(t, orderRestored.IsDeleted())
+	assert.Empty(t, orderRestored.DeletedBy)
+	assert.Equal(t, []entities.EventType{entities.OrderRestoredEvent}, eventTypes(existing))
+}
+
+func TestGetAll_IncludeDeleted(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("List", entities.OrderFilter{IncludeDeleted: true}).Return(&entities.OrderPage{}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos?include_deleted=true", nil)
+	req.Header.Set("X-Roles", "kitchen, admin")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	useCase.AssertExpectations(t)
+}
+
+func TestGetAll_IncludeDeletedRequiresAdmin(t *testing.T) {
+	for _, roles := range []string{"", "kitchen", "administrator"} {
+		useCase := new(mocks.OrderUseCase)
+		req, _ := http.NewRequest("GET", "/pedidos?include_deleted=true", nil)
+		req.Header.Set("X-Roles", roles)
+
+		res, problem := serveOrders(useCase, req)
+
+		assert.Equal(t, http.StatusForbidden, res.Code, roles)
+		assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+		assert.Equal(t, "admin_required", problem.Code)
+		useCase.AssertNotCalled(t, "List", mock.Anything)
+	}
+}
+
+func TestPOSTRestore_NotFound(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Restore", "1", "admin").Return(nil, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/pedidos/1/restore", nil)
+	req.Header.Set("X-Actor", "admin")
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatus_Transitions(t *testing.T) {
+	assert.True(t, entities.CreatedOrdersStatus.CanTransitionTo(entities.ApprovedPaymentOrderStatus))
+	assert.True(t, entities.ReceivedOrderStatus.CanTransitionTo(entities.CookingOrderStatus))
+	assert.True(t, entities.CookingOrderStatus.CanTransitionTo(entities.CookingOrderStatus))
+	assert.False(t, entities.DoneOrderStatus.CanTransitionTo(entities.CreatedOrdersStatus))
+	assert.False(t, entities.DeclinedPaymentOrderStatus.CanTransitionTo(entities.ReadyOrderStatus))
+	assert.False(t, entities.CreatedOrdersStatus.CanTransitionTo("ERRO"))
+	assert.Empty(t, entities.DoneOrderStatus.NextStatuses())
+}
+
+func TestUpdateOrderStatus_IllegalTransition(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(&entities.Order{OrderID: "1", Status: entities.DoneOrderStatus}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.UpdateOrderStatus("1", entities.CreatedOrdersStatus, "", "", 0)
+
+	assert.True(t, util.IsDomainError(err), "Domain error is expected")
+	assert.Contains(t, err.Error(), "FINALIZADO to CRIADO")
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateOrderStatus_Success(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(&entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}, nil)
+	orderGateway.On("Update", "1", mock.Anything, []entities.OrderField{entities.OrderStatusField}).
+		Return(&entities.Order{OrderID: "1", Status: entities.CookingOrderStatus}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderUpdated, err := useCase.UpdateOrderStatus("1", entities.CookingOrderStatus, "cozinha-1", "started", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.CookingOrderStatus, orderUpdated.Status)
+}
+
+func TestUpdateOrderStatus_RecordsHistory(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	orderUpdated, err := useCase.UpdateOrderStatus("1", entities.CookingOrderStatus, "cozinha-1", "started", 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, orderUpdated.StatusHistory, 1)
+	change := orderUpdated.StatusHistory[0]
+	assert.Equal(t, entities.ReceivedOrderStatus, change.From)
+	assert.Equal(t, entities.CookingOrderStatus, change.To)
+	assert.Equal(t, "cozinha-1", change.Actor)
+	assert.Equal(t, "started", change.Reason)
+}
+
+func TestGetStatusHistory_NotFound(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(nil, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos/1/history", nil)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestPATCH_ReturnsNextStatuses(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("UpdateOrderStatus", "1", entities.Status("RECEBIDO"), "", "", 0).
+		Return(&entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(`{"status":"RECEBIDO"}`))
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"next_statuses":["EM_PREPARACAO","CANCELADO"]`)
+}
+
+func TestPATCH_ReturnsNoNextStatusesForTerminalStatuses(t *testing.T) {
+	for _, status := range []entities.Status{entities.DoneOrderStatus, entities.CanceledOrderStatus} {
+		useCase := new(mocks.OrderUseCase)
+		useCase.On("UpdateOrderStatus", "1", status, "", "", 0).
+			Return(&entities.Order{OrderID: "1", Status: status}, nil)
+
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(`{"status":"`+string(status)+`"}`))
+
+		c := chi.NewRouter()
+		controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+
+		c.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"next_statuses":[]`, status)
+	}
+}
+
+func TestCreate_StartsAsCreated(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	newOrder := &entities.Order{
+		ClientID:     "123",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}
+	orderGateway.On("Save", newOrder).Return(newOrder, nil)
+	_, err := useCase.Create(newOrder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.CreatedOrdersStatus, newOrder.Status)
+	assert.Equal(t, entities.CreatedOrdersStatus, newOrder.StatusHistory[0].To)
+}
+
+func TestCreate_RejectsOtherStatuses(t *testing.T) {
+	for _, status := range []entities.Status{entities.DoneOrderStatus, entities.ApprovedPaymentOrderStatus, "ERRO"} {
+		orderGateway := new(mocks.OrderGateway)
+		itemGateway := new(mocks.ItemGateway)
+		itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+		useCase := order.NewUseCase(orderGateway, itemGateway)
+
+		_, err := useCase.Create(&entities.Order{
+			ClientID:     "123",
+			Status:       status,
+			OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+		})
+
+		domainErr, ok := util.AsErrorDomain(err)
+		if assert.True(t, ok, "Domain error is expected for %s", status) {
+			assert.Equal(t, util.ValidationCategory, domainErr.Category)
+			assert.Equal(t, "status", domainErr.Fields[0].Field)
+		}
+		orderGateway.AssertNotCalled(t, "Save", mock.Anything)
+	}
+}
+
+func TestUpdate_RejectsContentChangesOutsideEditableStatuses(t *testing.T) {
+	for _, status := range []entities.Status{entities.ApprovedPaymentOrderStatus, entities.ReceivedOrderStatus,
+		entities.DoneOrderStatus, entities.CanceledOrderStatus} {
+		existing := &entities.Order{OrderID: "1", Status: status,
+			OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}}}
+		orderGateway := new(mocks.OrderGateway)
+		orderGateway.On("GetByID", "1").Return(existing, nil)
+		itemGateway := new(mocks.ItemGateway)
+		itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+		useCase := order.NewUseCase(orderGateway, itemGateway)
+
+		_, err := useCase.Update("1", &entities.Order{
+			Status:       status,
+			OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 2}},
+		}, "", "")
+
+		domainErr, ok := util.AsErrorDomain(err)
+		if assert.True(t, ok, "status %s", status) {
+			assert.Equal(t, util.InvalidTransitionCategory, domainErr.Category)
+		}
+		orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestUpdate_ChangesOnlyTheStatusOfApprovedOrders(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ApprovedPaymentOrderStatus, Notes: "sem cebola",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1, Price: 10, LineTotal: 1000}}, Total: 1000}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	// The catalog price changed, the approved order keeps the price it was paid with
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 12}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	updated, err := useCase.Update("1", &entities.Order{
+		Status:       entities.ReceivedOrderStatus,
+		Notes:        "sem cebola",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}, "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ReceivedOrderStatus, updated.Status)
+	assert.Equal(t, entities.Cents(1000), updated.Total)
+	itemGateway.AssertNotCalled(t, "GetByID", mock.Anything)
+}
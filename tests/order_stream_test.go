@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/gateways/broadcast"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func orderEvent(orderID, clientID string, status entities.Status) entities.OrderEvent {
+	return entities.OrderEvent{
+		EventID: orderID + string(status),
+		Type:    entities.OrderStatusChangedEvent,
+		Data:    entities.OrderEventData{Order: entities.Order{OrderID: orderID, ClientID: clientID, Status: status}},
+	}
+}
+
+func TestBroadcaster_FiltersEvents(t *testing.T) {
+	broadcaster := broadcast.NewBroadcaster()
+	subscription := broadcaster.Subscribe(entities.StreamFilter{
+		ClientID: "123",
+		Statuses: []entities.Status{entities.ReadyOrderStatus},
+	}, 0)
+	defer subscription.Close()
+
+	broadcaster.Publish(orderEvent("1", "123", entities.CookingOrderStatus))
+	broadcaster.Publish(orderEvent("2", "456", entities.ReadyOrderStatus))
+	broadcaster.Publish(orderEvent("1", "123", entities.ReadyOrderStatus))
+
+	streamed := <-subscription.Events
+	assert.Equal(t, uint64(3), streamed.ID)
+	assert.Equal(t, "1PRONTO", streamed.Event.EventID)
+	assert.Empty(t, subscription.Events)
+}
+
+func TestBroadcaster_ReplaysAfterLastEventID(t *testing.T) {
+	broadcaster := broadcast.NewBroadcaster()
+	broadcaster.Publish(orderEvent("1", "123", entities.ReceivedOrderStatus))
+	broadcaster.Publish(orderEvent("2", "123", entities.ReceivedOrderStatus))
+	broadcaster.Publish(orderEvent("1", "123", entities.CookingOrderStatus))
+
+	subscription := broadcaster.Subscribe(entities.StreamFilter{OrderID: "1"}, 1)
+	defer subscription.Close()
+
+	assert.Len(t, subscription.Replay, 1)
+	assert.Equal(t, uint64(3), subscription.Replay[0].ID)
+}
+
+func TestBroadcaster_DropsSlowSubscribers(t *testing.T) {
+	broadcaster := broadcast.NewBroadcaster()
+	subscription := broadcaster.Subscribe(entities.StreamFilter{}, 0)
+	defer subscription.Close()
+
+	for i := 0; i < 100; i++ {
+		broadcaster.Publish(orderEvent("1", "123", entities.ReceivedOrderStatus))
+	}
+
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+	assert.Less(t, received, 100)
+}
+
+func TestUpdateOrderStatus_Broadcasts(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	broadcaster := broadcast.NewBroadcaster()
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+	useCase.Broadcaster = broadcaster
+
+	subscription, err := useCase.Subscribe(entities.StreamFilter{OrderID: "1"}, 0)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	_, err = useCase.UpdateOrderStatus("1", entities.CookingOrderStatus, "cook-1", "", 0)
+
+	assert.NoError(t, err)
+	streamed := <-subscription.Events
+	assert.Equal(t, entities.OrderStatusChangedEvent, streamed.Event.Type)
+	assert.Equal(t, entities.CookingOrderStatus, streamed.Event.Data.Order.Status)
+}
+
+func TestUpdateOrderStatus_FailedWriteIsNotBroadcast(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.ReceivedOrderStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(nil, errUsecaseFailure)
+	broadcaster := broadcast.NewBroadcaster()
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+	useCase.Broadcaster = broadcaster
+
+	subscription, _ := useCase.Subscribe(entities.StreamFilter{}, 0)
+	defer subscription.Close()
+
+	_, err := useCase.UpdateOrderStatus("1", entities.CookingOrderStatus, "cook-1", "", 0)
+
+	assert.Error(t, err)
+	assert.Empty(t, subscription.Events)
+}
+
+func startStreamServer(useCase *mocks.OrderUseCase) *httptest.Server {
+	c := chi.NewRouter()
+	controller := controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	controller.HeartbeatInterval = 10 * time.Millisecond
+	return httptest.NewServer(c)
+}
+
+// readEvent reads the next event of a Server-Sent Events stream as its lines
+func readEvent(reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		if err != nil || line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestGETStream_ResumesAndSendsEvents(t *testing.T) {
+	events := make(chan entities.StreamedEvent, 1)
+	events <- entities.StreamedEvent{ID: 9, Event: orderEvent("1", "123", entities.ReadyOrderStatus)}
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Subscribe", entities.StreamFilter{
+		ClientID: "123",
+		Statuses: []entities.Status{entities.ReadyOrderStatus, entities.DeliveredOrderStatus},
+	}, uint64(7)).Return(&entities.OrderSubscription{
+		Replay: []entities.StreamedEvent{{ID: 8, Event: orderEvent("1", "123", entities.CookingOrderStatus)}},
+		Events: events,
+		Close:  func() {},
+	}, nil)
+	server := startStreamServer(useCase)
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/pedidos/stream?client_id=123&status=PRONTO,ENTREGUE", nil)
+	req.Header.Set("Last-Event-ID", "7")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	reader := bufio.NewReader(res.Body)
+	replayed := readEvent(reader)
+	assert.Equal(t, []string{"id: 8", "event: OrderStatusChanged"}, replayed[:2])
+	assert.Contains(t, replayed[2], `"status":"EM_PREPARACAO"`)
+	live := readEvent(reader)
+	assert.Equal(t, "id: 9", live[0])
+	assert.Equal(t, "event: heartbeat", readEvent(reader)[0])
+}
+
+func TestGETStream_InvalidLastEventID(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	req, _ := http.NewRequest("GET", "/pedidos/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "invalid_last_event_id", problem.Code)
+	useCase.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+}
+
+func TestGETOrderStream_NotFound(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(nil, nil)
+	req, _ := http.NewRequest("GET", "/pedidos/1/stream", nil)
+
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "order_not_found", problem.Code)
+	useCase.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+}
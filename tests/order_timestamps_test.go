@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate_TimestampsFromClock(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("-03", -3*60*60))
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("Save", mock.Anything).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+	useCase.Clock = func() time.Time { return createdAt }
+
+	newOrder := &entities.Order{
+		ClientID:     "123",
+		Status:       entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}
+	_, err := useCase.Create(newOrder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt.UTC(), newOrder.CreatedAt)
+	assert.Equal(t, createdAt.UTC(), newOrder.UpdatedAt)
+	assert.Equal(t, createdAt.UTC(), newOrder.StatusHistory[0].ChangedAt)
+	assert.Equal(t, createdAt.UTC(), newOrder.PendingEvents[0].Event.OccurredAt)
+}
+
+func TestGET_TimestampsInRFC3339UTC(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("-03", -3*60*60))
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(&entities.Order{OrderID: "1", CreatedAt: createdAt, UpdatedAt: createdAt}, nil)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/pedidos/1", nil)
+
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, strings.Contains(res.Body.String(), `"created_at":"2024-03-01T12:30:00Z"`), res.Body.String())
+	assert.NotContains(t, res.Body.String(), "deleted_at")
+}
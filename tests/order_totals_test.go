@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	Order "github.com/postech-soat2-grupo16/pedidos-api/adapters/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate_CalculatesTotals(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("Save", mock.Anything).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 0.1}, nil)
+	itemGateway.On("GetByID", "2").Return(&entities.Item{ItemID: "2", Price: 19.99}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	newOrder := &entities.Order{
+		ClientID: "123",
+		Status:   entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{
+			{ItemID: "1", Quantity: 3},
+			{ItemID: "2", Quantity: 2},
+		},
+	}
+	_, err := useCase.Create(newOrder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.Cents(30), newOrder.OrderedItems[0].LineTotal)
+	assert.Equal(t, entities.Cents(3998), newOrder.OrderedItems[1].LineTotal)
+	assert.Equal(t, entities.Cents(4028), newOrder.Subtotal)
+	assert.Equal(t, entities.Cents(4028), newOrder.Total)
+}
+
+func TestCreate_IgnoresClientDiscount(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("Save", mock.Anything).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	newOrder := &entities.Order{
+		ClientID:     "123",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+		Discount:     1000,
+	}
+	_, err := useCase.Create(newOrder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.Cents(0), newOrder.Discount)
+	assert.Equal(t, entities.Cents(1000), newOrder.Total)
+}
+
+func TestUpdate_KeepsStoredDiscount(t *testing.T) {
+	existing := &entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(existing, nil)
+	orderGateway.On("Update", "1", existing, mock.Anything).Return(existing, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	_, err := useCase.Update("1", &entities.Order{
+		Status:       entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 2}},
+		Discount:     2000,
+	}, "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.Cents(0), existing.Discount)
+	assert.Equal(t, entities.Cents(2000), existing.Total)
+}
+
+func TestPOST_IgnoresDiscountCents(t *testing.T) {
+	var orderModel Order.Order
+	assert.NoError(t, json.Unmarshal([]byte(`{"client_id": "123", "discount_cents": 1000}`), &orderModel))
+
+	assert.Equal(t, entities.Cents(0), orderModel.ToUseCaseEntity().Discount)
+}
+
+func TestUpdate_OnlyChangedFields(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").
+		Return(&entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}, nil)
+	orderGateway.On("Update", "1", mock.Anything,
+		[]entities.OrderField{entities.OrderItemsField, entities.OrderNotesField}).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	_, err := useCase.Update("1", &entities.Order{
+		Status:       entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 2}},
+		Notes:        "sem cebola",
+	}, "", "")
+
+	assert.NoError(t, err)
+	orderGateway.AssertExpectations(t)
+	orderGateway.AssertNotCalled(t, "Save", mock.Anything)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate_ReturnsEveryViolation(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	itemGateway.On("GetByID", "unknown").Return(nil, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	_, err := useCase.Create(&entities.Order{
+		OrderedItems: []entities.OrderedItem{
+			{ItemID: "1", Quantity: -1},
+			{ItemID: "", Quantity: 100},
+			{ItemID: "unknown", Quantity: 1},
+		},
+		Notes:  strings.Repeat("a", 501),
+		Status: entities.DoneOrderStatus,
+	})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok, "Domain error is expected")
+	assert.Equal(t, util.ValidationCategory, domainErr.Category)
+	var fields []string
+	for _, field := range domainErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"client_id",
+		"ordered_items[0].quantity",
+		"ordered_items[1].item_id",
+		"ordered_items[1].quantity",
+		"ordered_items[2].item_id",
+		"notes",
+		"status",
+	}, fields)
+	orderGateway.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestCreate_RequiresItems(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	_, err := useCase.Create(&entities.Order{ClientID: "123"})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok, "Domain error is expected")
+	assert.Equal(t, []util.FieldError{{Field: "ordered_items", Message: "must have at least one item"}}, domainErr.Fields)
+}
+
+func TestCreate_ZeroCatalogPrice(t *testing.T) {
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 0}, nil)
+	useCase := order.NewUseCase(new(mocks.OrderGateway), itemGateway)
+
+	_, err := useCase.Create(&entities.Order{
+		ClientID:     "123",
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok, "Domain error is expected")
+	assert.Equal(t, "ordered_items[0].price", domainErr.Fields[0].Field)
+}
+
+func TestPOST_UnknownField(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"client_id": "123", "clientid": "123"}`))
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "unknown_field", problem.Code)
+	assert.Equal(t, []util.FieldError{{Field: "clientid", Message: "is not a known field"}}, problem.Errors)
+	useCase.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPOST_BodyTooLarge(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+
+	body := `{"notes": "` + strings.Repeat("a", 100<<10) + `"}`
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(body))
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "body_too_large", problem.Code)
+	useCase.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUpdate_ReportsInvalidStatusWithOtherViolations(t *testing.T) {
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("GetByID", "1").Return(&entities.Order{OrderID: "1", Status: entities.CreatedOrdersStatus}, nil)
+	useCase := order.NewUseCase(orderGateway, new(mocks.ItemGateway))
+
+	for _, status := range []entities.Status{"", "ERRO"} {
+		_, err := useCase.Update("1", &entities.Order{Status: status}, "", "")
+
+		domainErr, ok := util.AsErrorDomain(err)
+		if assert.True(t, ok, "Domain error is expected for %q", status) {
+			assert.Equal(t, util.ValidationCategory, domainErr.Category)
+			assert.Equal(t, []util.FieldError{
+				{Field: "status", Message: "is not an order status"},
+				{Field: "ordered_items", Message: "must have at least one item"},
+			}, domainErr.Fields)
+		}
+	}
+	orderGateway.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
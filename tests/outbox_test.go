@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/order"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeOutbox keeps the outbox events in memory
+type fakeOutbox struct {
+	events map[string]*entities.OutboxEvent
+}
+
+func newFakeOutbox() *fakeOutbox {
+	return &fakeOutbox{events: map[string]*entities.OutboxEvent{}}
+}
+
+func (f *fakeOutbox) GetPending(now time.Time, limit int) ([]entities.OutboxEvent, error) {
+	var pending []entities.OutboxEvent
+	for _, event := range f.events {
+		if event.Status == entities.PendingOutboxStatus && !event.NextAttemptAt.After(now) && len(pending) < limit {
+			pending = append(pending, *event)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeOutbox) Save(event *entities.OutboxEvent) error {
+	saved := *event
+	f.events[event.EventID] = &saved
+	return nil
+}
+
+func TestCreate_PublishesThroughOutbox(t *testing.T) {
+	outboxGateway := newFakeOutbox()
+	orderGateway := new(mocks.OrderGateway)
+	orderGateway.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		// The order and its events are saved together, like the DynamoDB transaction does
+		for _, event := range args.Get(0).(*entities.Order).PendingEvents {
+			outboxGateway.Save(&event)
+		}
+	}).Return(&entities.Order{}, nil)
+	itemGateway := new(mocks.ItemGateway)
+	itemGateway.On("GetByID", "1").Return(&entities.Item{ItemID: "1", Price: 10}, nil)
+	useCase := order.NewUseCase(orderGateway, itemGateway)
+
+	queueGateway := new(mocks.QueueGateway)
+	queueGateway.On("Publish", mock.Anything).Return(errors.New("queue unavailable")).Once()
+	queueGateway.On("Publish", mock.Anything).Return(nil)
+	relay := outbox.NewRelay(outboxGateway, queueGateway)
+
+	orderCreated := &entities.Order{
+		ClientID:     "123",
+		Status:       entities.CreatedOrdersStatus,
+		OrderedItems: []entities.OrderedItem{{ItemID: "1", Quantity: 1}},
+	}
+	_, err := useCase.Create(orderCreated)
+	assert.NoError(t, err)
+	assert.Len(t, outboxGateway.events, 1)
+	queueGateway.AssertNotCalled(t, "Publish", mock.Anything)
+
+	// First attempt fails and is scheduled for a retry
+	assert.NoError(t, relay.PublishPending())
+	var event *entities.OutboxEvent
+	for _, e := range outboxGateway.events {
+		event = e
+	}
+	assert.Equal(t, entities.PendingOutboxStatus, event.Status)
+	assert.Equal(t, 1, event.Attempts)
+	assert.Equal(t, "queue unavailable", event.LastError)
+
+	// Retry once the backoff has passed
+	event.NextAttemptAt = time.Now().Add(-time.Second)
+	assert.NoError(t, relay.PublishPending())
+	event = outboxGateway.events[event.EventID]
+	assert.Equal(t, entities.SentOutboxStatus, event.Status)
+	assert.Equal(t, entities.OrderCreatedEvent, event.Event.Type)
+	assert.Equal(t, orderCreated.OrderID, event.Event.Data.Order.OrderID)
+	queueGateway.AssertNumberOfCalls(t, "Publish", 2)
+}
+
+func TestRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	outboxGateway := newFakeOutbox()
+	outboxGateway.Save(&entities.OutboxEvent{EventID: "1", Status: entities.PendingOutboxStatus, Attempts: 2})
+	queueGateway := new(mocks.QueueGateway)
+	queueGateway.On("Publish", mock.Anything).Return(errors.New("queue unavailable"))
+	relay := outbox.NewRelay(outboxGateway, queueGateway)
+	relay.MaxAttempts = 3
+
+	assert.NoError(t, relay.PublishPending())
+
+	assert.Equal(t, entities.FailedOutboxStatus, outboxGateway.events["1"].Status)
+}
+
+func TestRelay_EventsInBackoffDoNotHoldBackNewerOnes(t *testing.T) {
+	outboxGateway := obg.NewMemoryGateway()
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// More events in backoff than a batch holds, all older than the event that is due
+	for i := 1; i <= 3; i++ {
+		outboxGateway.Save(&entities.OutboxEvent{
+			EventID:       fmt.Sprintf("backoff-%d", i),
+			Status:        entities.PendingOutboxStatus,
+			Attempts:      3,
+			CreatedAt:     now.Add(-time.Hour),
+			NextAttemptAt: now.Add(time.Minute),
+		})
+	}
+	outboxGateway.Save(&entities.OutboxEvent{
+		EventID:       "due",
+		Event:         entities.OrderEvent{EventID: "due"},
+		Status:        entities.PendingOutboxStatus,
+		CreatedAt:     now.Add(-time.Second),
+		NextAttemptAt: now.Add(-time.Second),
+	})
+	queueGateway := new(mocks.QueueGateway)
+	queueGateway.On("Publish", mock.Anything).Return(nil)
+	relay := outbox.NewRelay(outboxGateway, queueGateway)
+	relay.BatchSize = 2
+	relay.Clock = func() time.Time { return now }
+
+	assert.NoError(t, relay.PublishPending())
+
+	queueGateway.AssertNumberOfCalls(t, "Publish", 1)
+	queueGateway.AssertCalled(t, "Publish", &entities.OrderEvent{EventID: "due"})
+	pending, _ := outboxGateway.GetPending(now.Add(time.Hour), 10)
+	assert.Len(t, pending, 3, "the events in backoff are still pending")
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeReceiver is an in-memory queue that keeps the messages until they are deleted
+type fakeReceiver struct {
+	messages []entities.QueueMessage
+	deleted  []string
+}
+
+func (f *fakeReceiver) ReceiveMessages(maxMessages int) ([]entities.QueueMessage, error) {
+	if len(f.messages) > maxMessages {
+		return f.messages[:maxMessages], nil
+	}
+	return f.messages, nil
+}
+
+func (f *fakeReceiver) DeleteMessage(receiptHandle string) error {
+	f.deleted = append(f.deleted, receiptHandle)
+	var remaining []entities.QueueMessage
+	for _, message := range f.messages {
+		if message.ReceiptHandle != receiptHandle {
+			remaining = append(remaining, message)
+		}
+	}
+	f.messages = remaining
+	return nil
+}
+
+func TestPaymentConsumer_AdvancesOrders(t *testing.T) {
+	receiver := &fakeReceiver{messages: []entities.QueueMessage{
+		{MessageID: "1", ReceiptHandle: "r1", Body: `{"order_id":"10","payment_id":"p1","status":"APROVADO"}`},
+		{MessageID: "2", ReceiptHandle: "r2", Body: `{"order_id":"20","payment_id":"p2","status":"NEGADO"}`},
+	}}
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("UpdateOrderStatus", "10", entities.ApprovedPaymentOrderStatus, mock.Anything, "payment p1", 0).
+		Return(&entities.Order{OrderID: "10"}, nil)
+	useCase.On("UpdateOrderStatus", "20", entities.DeclinedPaymentOrderStatus, mock.Anything, "payment p2", 0).
+		Return(&entities.Order{OrderID: "20"}, nil)
+
+	err := controllers.NewPaymentConsumer(useCase, receiver).ConsumeBatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, receiver.deleted)
+	useCase.AssertExpectations(t)
+}
+
+func TestPaymentConsumer_KeepsPoisonMessages(t *testing.T) {
+	receiver := &fakeReceiver{messages: []entities.QueueMessage{
+		{MessageID: "1", ReceiptHandle: "invalid-json", Body: `{"order_id"`},
+		{MessageID: "2", ReceiptHandle: "unknown-status", Body: `{"order_id":"10","status":"PRONTO"}`},
+		{MessageID: "3", ReceiptHandle: "not-found", Body: `{"order_id":"404","status":"APROVADO"}`},
+		{MessageID: "4", ReceiptHandle: "illegal", Body: `{"order_id":"30","status":"APROVADO"}`},
+	}}
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("UpdateOrderStatus", "404", mock.Anything, mock.Anything, mock.Anything, 0).Return(nil, nil)
+	useCase.On("UpdateOrderStatus", "30", mock.Anything, mock.Anything, mock.Anything, 0).
+		Return(nil, util.NewErrorDomain("Transition from FINALIZADO to APROVADO is not allowed"))
+
+	err := controllers.NewPaymentConsumer(useCase, receiver).ConsumeBatch()
+
+	assert.NoError(t, err)
+	assert.Empty(t, receiver.deleted)
+	assert.Len(t, receiver.messages, 4)
+}
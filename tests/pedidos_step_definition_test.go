@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cucumber/godog"
+	Order "github.com/postech-soat2-grupo16/pedidos-api/adapters/order"
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 )
 
@@ -16,7 +18,7 @@ func parameterID(pedidoID string) error {
 
 	var orderedItems []entities.OrderedItem
 	orderedItems = append(orderedItems, entities.OrderedItem{
-		ItemID:      "1",
+		ItemID:      catalogItemID,
 		Price:       10,
 		Quantity:    1,
 		Name:        "nome",
@@ -29,8 +31,8 @@ func parameterID(pedidoID string) error {
 		Status:       "CRIADO",
 		OrderedItems: orderedItems,
 		Notes:        "nota",
-		CreatedAt:    time.Now().String(),
-		UpdatedAt:    time.Now().String(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	inputs.order = newOrder
@@ -40,7 +42,12 @@ func parameterID(pedidoID string) error {
 }
 
 func requestPOSTPedido() error {
-	pedidoItem := inputs.order
+	pedidoItem := Order.Order{
+		ClientID:     strconv.Itoa(inputs.clientID),
+		Status:       string(entities.CreatedOrdersStatus),
+		OrderedItems: []Order.OrderedItem{{ItemID: catalogItemID, Quantity: 1}},
+		Notes:        "nota",
+	}
 	body, err := json.Marshal(pedidoItem)
 	if err != nil {
 		return err
@@ -74,14 +81,19 @@ func getFirstOrderID() error {
 		return err
 	}
 
-	var orders []entities.Order
-	err = json.NewDecoder(res.Body).Decode(&orders)
+	var page struct {
+		Orders []entities.Order `json:"orders"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&page)
 	if err != nil {
 		return err
 	}
+	if len(page.Orders) == 0 {
+		return fmt.Errorf("no orders found")
+	}
 
-	inputs.firstOrder = orders[0]
-	inputs.pedidoID = orders[0].OrderID
+	inputs.firstOrder = page.Orders[0]
+	inputs.pedidoID = page.Orders[0].OrderID
 	return nil
 }
 
@@ -131,10 +143,10 @@ func requestGETPedidoWithClientID() error {
 }
 
 func requestPATCHPedidoWithStatus(arg1 string) error {
-	order := entities.Order{
-		Status: entities.Status(arg1),
+	statusUpdate := Order.StatusUpdate{
+		Status: arg1,
 	}
-	body, err := json.Marshal(order)
+	body, err := json.Marshal(statusUpdate)
 	if err != nil {
 		return err
 	}
@@ -151,8 +163,9 @@ func requestPATCHPedidoWithStatus(arg1 string) error {
 }
 
 func requestPUTPedidoWithStatus(arg1 string) error {
-	order := entities.Order{
-		Status: entities.Status(arg1),
+	order := Order.Order{
+		Status:       arg1,
+		OrderedItems: []Order.OrderedItem{{ItemID: catalogItemID, Quantity: 1}},
 	}
 	body, err := json.Marshal(order)
 	if err != nil {
@@ -1,18 +1,25 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"testing"
 
 	"github.com/cucumber/godog"
+	"github.com/postech-soat2-grupo16/pedidos-api/adapters/item"
 	"github.com/postech-soat2-grupo16/pedidos-api/api"
+	"github.com/postech-soat2-grupo16/pedidos-api/config"
 	"github.com/postech-soat2-grupo16/pedidos-api/tests/tutils"
 )
 
 var baseURL string
 
+// catalogItemID is the item the orders of the scenarios are made of
+var catalogItemID string
+
 func TestFeatures(t *testing.T) {
 	server := setup()
 	defer server.Close()
@@ -33,10 +40,13 @@ func TestFeatures(t *testing.T) {
 
 func setup() *http.Server {
 	os.Setenv("IS_LOCAL", "true")
-	os.Setenv("AWS_ACCESS_KEY_ID", "test")
-	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
-	db := api.SetupDB()
-	r := api.SetupRouter(db, nil)
+	os.Setenv("STORAGE_BACKEND", config.MemoryStorage)
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+	db := api.SetupDB(cfg)
+	r := api.SetupRouter(cfg, db, nil)
 
 	server := http.Server{
 		Handler: r,
@@ -44,5 +54,28 @@ func setup() *http.Server {
 	serverAddress := tutils.StartNewTestServer(&server)
 	baseURL = fmt.Sprintf("http://%s", serverAddress)
 
+	if err := createCatalogItem(); err != nil {
+		panic(err)
+	}
+
 	return &server
 }
+
+func createCatalogItem() error {
+	body, err := json.Marshal(item.Item{Name: "nome", Category: "categoria", Description: "descricao", Price: 10})
+	if err != nil {
+		return err
+	}
+	res, err := http.Post(fmt.Sprintf("%s/itens", baseURL), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var created item.Item
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return err
+	}
+	catalogItemID = created.ItemID
+	return nil
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serveOrders(useCase *mocks.OrderUseCase, req *http.Request) (*httptest.ResponseRecorder, controllers.Problem) {
+	res := httptest.NewRecorder()
+	c := chi.NewRouter()
+	controllers.NewOrderController(useCase, new(mocks.IdempotencyUseCase), c)
+	c.ServeHTTP(res, req)
+
+	var problem controllers.Problem
+	json.NewDecoder(res.Body).Decode(&problem)
+	return res, problem
+}
+
+func TestProblem_ValidationWithFieldErrors(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("Create", mock.Anything).Return(nil, util.NewValidationError("invalid_discount", "Discount must not be negative",
+		util.FieldError{Field: "discount_cents", Message: "must not be negative"}))
+
+	req, _ := http.NewRequest("POST", "/pedidos", strings.NewReader(`{"discount_cents": -1}`))
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+	assert.Equal(t, "urn:pedidos-api:problem:invalid_discount", problem.Type)
+	assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+	assert.Equal(t, "Discount must not be negative", problem.Detail)
+	assert.Equal(t, "/pedidos", problem.Instance)
+	assert.Equal(t, []util.FieldError{{Field: "discount_cents", Message: "must not be negative"}}, problem.Errors)
+}
+
+func TestProblem_StatusFromCategory(t *testing.T) {
+	cases := map[int]error{
+		http.StatusBadRequest:          util.NewBadRequestError("invalid_cursor", "Invalid cursor"),
+		http.StatusNotFound:            util.NewNotFoundError("order_not_found", "Order ID 1 not found"),
+		http.StatusConflict:            util.NewConflictError("version_mismatch", "Order 1 is at version 4, not 3"),
+		http.StatusUnprocessableEntity: util.NewInvalidTransitionError("transition_not_allowed", "Transition not allowed"),
+		http.StatusForbidden:           util.NewForbiddenError("admin_required", "Only admins can list soft deleted orders"),
+	}
+
+	for status, err := range cases {
+		useCase := new(mocks.OrderUseCase)
+		useCase.On("UpdateOrderStatus", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, err)
+
+		req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(`{"status": "PRONTO"}`))
+		res, problem := serveOrders(useCase, req)
+
+		domainErr, _ := util.AsErrorDomain(err)
+		assert.Equal(t, status, res.Code)
+		assert.Equal(t, status, problem.Status)
+		assert.Equal(t, domainErr.Code, problem.Code)
+		assert.Equal(t, "/pedidos/1", problem.Instance)
+	}
+}
+
+func TestProblem_WrappedDomainErrorKeepsItsStatus(t *testing.T) {
+	cases := map[int]error{
+		http.StatusNotFound: fmt.Errorf("loading order: %w", util.NewNotFoundError("order_not_found", "Order ID 1 not found")),
+		http.StatusConflict: fmt.Errorf("saving order: %w", util.NewConflictError("version_mismatch", "Order 1 changed")),
+	}
+
+	for status, err := range cases {
+		useCase := new(mocks.OrderUseCase)
+		useCase.On("UpdateOrderStatus", "1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, err)
+
+		req, _ := http.NewRequest("PATCH", "/pedidos/1", strings.NewReader(`{"status": "PRONTO"}`))
+		res, problem := serveOrders(useCase, req)
+
+		assert.Equal(t, status, res.Code)
+		assert.Equal(t, status, problem.Status)
+	}
+	assert.True(t, util.IsConflictError(cases[http.StatusConflict]))
+}
+
+func TestProblem_NotFound(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/pedidos/1", nil)
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "order_not_found", problem.Code)
+	assert.Equal(t, "Order ID 1 not found", problem.Detail)
+}
+
+func TestProblem_UnexpectedErrorIsNotExposed(t *testing.T) {
+	useCase := new(mocks.OrderUseCase)
+	useCase.On("GetByID", "1").Return(nil, errUsecaseFailure)
+
+	req, _ := http.NewRequest("GET", "/pedidos/1", nil)
+	res, problem := serveOrders(useCase, req)
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Empty(t, problem.Detail)
+}
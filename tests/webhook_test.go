@@ -0,0 +1,469 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/postech-soat2-grupo16/pedidos-api/controllers"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	obg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/outbox"
+	whg "github.com/postech-soat2-grupo16/pedidos-api/gateways/db/webhook"
+	whs "github.com/postech-soat2-grupo16/pedidos-api/gateways/webhook"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces/mocks"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/outbox"
+	"github.com/postech-soat2-grupo16/pedidos-api/usecases/webhook"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const webhookSecret = "0123456789abcdef"
+
+var webhookNow = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+// webhookReceiver answers every delivery with status and keeps the last request and body it got
+type webhookReceiver struct {
+	status  int
+	request *http.Request
+	body    []byte
+}
+
+func (rec *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec.request = r
+	rec.body, _ = io.ReadAll(r.Body)
+	w.WriteHeader(rec.status)
+}
+
+func newWebhookUseCase(webhookGateway *mocks.WebhookGateway) webhook.UseCase {
+	// The test receivers listen on loopback
+	sender := whs.NewSender()
+	sender.AllowPrivateAddresses = true
+	useCase := webhook.NewUseCase(webhookGateway, sender)
+	useCase.Clock = func() time.Time { return webhookNow }
+	return useCase
+}
+
+func pendingDelivery() entities.WebhookDelivery {
+	return entities.WebhookDelivery{
+		DeliveryID:     "d1",
+		SubscriptionID: "s1",
+		EventType:      entities.OrderStatusChangedEvent,
+		Payload:        `{"type":"OrderStatusChanged"}`,
+		Status:         entities.PendingDeliveryStatus,
+		NextAttemptAt:  webhookNow,
+	}
+}
+
+func TestDispatch_QueuesDeliveriesOfMatchingSubscriptions(t *testing.T) {
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetAllSubscriptions").Return([]entities.WebhookSubscription{
+		{SubscriptionID: "all", Active: true},
+		{SubscriptionID: "ready", Active: true, Statuses: []entities.Status{entities.ReadyOrderStatus}},
+		{SubscriptionID: "cancellations", Active: true, EventTypes: []entities.EventType{entities.OrderCancelledEvent}},
+		{SubscriptionID: "disabled", Active: false},
+	}, nil)
+	var saved []string
+	webhookGateway.On("CreateDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).(*entities.WebhookDelivery).SubscriptionID)
+	}).Return(true, nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	err := useCase.Dispatch(&entities.OrderEvent{
+		EventID: "e1",
+		Type:    entities.OrderStatusChangedEvent,
+		Data:    entities.OrderEventData{Order: entities.Order{OrderID: "1", Status: entities.ReadyOrderStatus}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"all", "ready"}, saved)
+}
+
+func TestDispatch_IgnoresEventsThatAreNotStatusChanges(t *testing.T) {
+	webhookGateway := new(mocks.WebhookGateway)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	err := useCase.Dispatch(&entities.OrderEvent{Type: entities.OrderCreatedEvent})
+
+	assert.NoError(t, err)
+	webhookGateway.AssertNotCalled(t, "GetAllSubscriptions")
+}
+
+func TestDeliverPending_SignsDelivery(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	delivery := pendingDelivery()
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetPendingDeliveries", webhookNow, 25).Return([]entities.WebhookDelivery{delivery}, nil)
+	webhookGateway.On("GetSubscription", "s1").
+		Return(&entities.WebhookSubscription{SubscriptionID: "s1", URL: server.URL, Secret: webhookSecret, Active: true}, nil)
+	var saved *entities.WebhookDelivery
+	webhookGateway.On("SaveDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*entities.WebhookDelivery)
+	}).Return(nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	err := useCase.DeliverPending()
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.DeliveredDeliveryStatus, saved.Status)
+	assert.Equal(t, http.StatusNoContent, saved.ResponseStatus)
+	assert.Equal(t, delivery.Payload, string(receiver.body))
+	timestamp, _ := strconv.ParseInt(receiver.request.Header.Get(webhook.TimestampHeader), 10, 64)
+	assert.Equal(t, webhookNow.Unix(), timestamp)
+	assert.Equal(t, webhook.Sign(webhookSecret, timestamp, receiver.body), receiver.request.Header.Get(webhook.SignatureHeader))
+	assert.Equal(t, "d1", receiver.request.Header.Get(webhook.DeliveryHeader))
+	assert.Equal(t, "OrderStatusChanged", receiver.request.Header.Get(webhook.EventHeader))
+}
+
+func TestDeliverPending_RetriesWithBackoff(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	delivery := pendingDelivery()
+	delivery.Attempts = 2
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetPendingDeliveries", webhookNow, 25).Return([]entities.WebhookDelivery{delivery}, nil)
+	webhookGateway.On("GetSubscription", "s1").
+		Return(&entities.WebhookSubscription{SubscriptionID: "s1", URL: server.URL, Secret: webhookSecret, Active: true}, nil)
+	var saved *entities.WebhookDelivery
+	webhookGateway.On("SaveDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*entities.WebhookDelivery)
+	}).Return(nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	err := useCase.DeliverPending()
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PendingDeliveryStatus, saved.Status)
+	assert.Equal(t, 3, saved.Attempts)
+	assert.Equal(t, webhookNow.Add(40*time.Second), saved.NextAttemptAt)
+	assert.Contains(t, saved.LastError, "500")
+}
+
+func TestDeliverPending_GivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusBadGateway}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	delivery := pendingDelivery()
+	delivery.Attempts = 7
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetPendingDeliveries", webhookNow, 25).Return([]entities.WebhookDelivery{delivery}, nil)
+	webhookGateway.On("GetSubscription", "s1").
+		Return(&entities.WebhookSubscription{SubscriptionID: "s1", URL: server.URL, Secret: webhookSecret, Active: true}, nil)
+	var saved *entities.WebhookDelivery
+	webhookGateway.On("SaveDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*entities.WebhookDelivery)
+	}).Return(nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	assert.NoError(t, useCase.DeliverPending())
+	assert.Equal(t, entities.FailedDeliveryStatus, saved.Status)
+}
+
+func TestDeliverPending_DeliveriesInBackoffDoNotHoldBackNewerOnes(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhookGateway := whg.NewMemoryGateway()
+	webhookGateway.SaveSubscription(&entities.WebhookSubscription{
+		SubscriptionID: "s1", URL: server.URL, Secret: webhookSecret, Active: true,
+	})
+	// More deliveries in backoff than a batch holds, all older than the delivery that is due
+	for i := 1; i <= 3; i++ {
+		delivery := pendingDelivery()
+		delivery.DeliveryID = fmt.Sprintf("backoff-%d", i)
+		delivery.Attempts = 3
+		delivery.CreatedAt = webhookNow.Add(-time.Hour)
+		delivery.NextAttemptAt = webhookNow.Add(time.Minute)
+		webhookGateway.SaveDelivery(&delivery)
+	}
+	due := pendingDelivery()
+	due.DeliveryID = "due"
+	due.CreatedAt = webhookNow.Add(-time.Second)
+	due.NextAttemptAt = webhookNow.Add(-time.Second)
+	webhookGateway.SaveDelivery(&due)
+	sender := whs.NewSender()
+	sender.AllowPrivateAddresses = true
+	useCase := webhook.NewUseCase(webhookGateway, sender)
+	useCase.Clock = func() time.Time { return webhookNow }
+	useCase.BatchSize = 2
+
+	assert.NoError(t, useCase.DeliverPending())
+
+	assert.Equal(t, "due", receiver.request.Header.Get(webhook.DeliveryHeader))
+	delivered, _ := webhookGateway.GetDelivery("due")
+	assert.Equal(t, entities.DeliveredDeliveryStatus, delivered.Status)
+	pending, _ := webhookGateway.GetPendingDeliveries(webhookNow.Add(time.Hour), 10)
+	assert.Len(t, pending, 3, "the deliveries in backoff are still pending")
+}
+
+func TestRedeliver_SendsFailedDeliveryAgain(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	delivery := pendingDelivery()
+	delivery.Status = entities.FailedDeliveryStatus
+	delivery.Attempts = 8
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetDelivery", "d1").Return(&delivery, nil)
+	webhookGateway.On("GetSubscription", "s1").
+		Return(&entities.WebhookSubscription{SubscriptionID: "s1", URL: server.URL, Secret: webhookSecret, Active: true}, nil)
+	webhookGateway.On("SaveDelivery", &delivery).Return(nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	redelivered, err := useCase.Redeliver("s1", "d1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.DeliveredDeliveryStatus, redelivered.Status)
+	assert.Equal(t, 1, redelivered.Attempts)
+	assert.Equal(t, delivery.Payload, string(receiver.body))
+}
+
+func TestRedeliver_RefusesInactiveSubscription(t *testing.T) {
+	delivery := pendingDelivery()
+	delivery.Status = entities.FailedDeliveryStatus
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetDelivery", "d1").Return(&delivery, nil)
+	webhookGateway.On("GetSubscription", "s1").
+		Return(&entities.WebhookSubscription{SubscriptionID: "s1", URL: "https://example.com", Secret: webhookSecret}, nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	_, err := useCase.Redeliver("s1", "d1")
+
+	domainErr, ok := util.AsErrorDomain(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "subscription_inactive", domainErr.Code)
+		assert.Equal(t, util.ValidationCategory, domainErr.Category)
+	}
+	assert.Equal(t, entities.FailedDeliveryStatus, delivery.Status)
+	webhookGateway.AssertNotCalled(t, "SaveDelivery", mock.Anything)
+}
+
+func TestRedeliver_DeliveryOfAnotherSubscription(t *testing.T) {
+	delivery := pendingDelivery()
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetDelivery", "d1").Return(&delivery, nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	_, err := useCase.Redeliver("s2", "d1")
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	assert.Equal(t, util.NotFoundCategory, domainErr.Category)
+}
+
+func TestCreateSubscription_Validation(t *testing.T) {
+	webhookGateway := new(mocks.WebhookGateway)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	_, err := useCase.CreateSubscription(&entities.WebhookSubscription{
+		URL:        "ftp://partner",
+		Secret:     "short",
+		EventTypes: []entities.EventType{entities.OrderCreatedEvent},
+		Statuses:   []entities.Status{"PERDIDO"},
+	})
+
+	domainErr, ok := util.AsErrorDomain(err)
+	assert.True(t, ok)
+	var fields []string
+	for _, field := range domainErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"url", "secret", "event_types[0]", "statuses[0]"}, fields)
+	webhookGateway.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+}
+
+func TestCreateSubscription_RejectsInternalAddresses(t *testing.T) {
+	for _, target := range []string{"http://localhost:8000/hook", "http://127.0.0.1/hook", "http://10.0.0.5/hook",
+		"http://192.168.1.1/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook", "http://0.0.0.0/hook"} {
+		webhookGateway := new(mocks.WebhookGateway)
+		useCase := newWebhookUseCase(webhookGateway)
+
+		_, err := useCase.CreateSubscription(&entities.WebhookSubscription{
+			URL:        target,
+			Secret:     webhookSecret,
+			EventTypes: []entities.EventType{entities.OrderStatusChangedEvent},
+		})
+
+		domainErr, ok := util.AsErrorDomain(err)
+		if assert.True(t, ok, target) {
+			assert.Equal(t, "url", domainErr.Fields[0].Field, target)
+		}
+		webhookGateway.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+	}
+}
+
+func TestSender_RefusesPrivateAddresses(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	_, err := whs.NewSender().Send(server.URL, nil, []byte(`{}`))
+
+	assert.ErrorIs(t, err, whs.ErrPrivateAddress)
+	assert.Nil(t, receiver.request)
+}
+
+func TestSender_DoesNotFollowRedirects(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	target := httptest.NewServer(receiver)
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+	sender := whs.NewSender()
+	sender.AllowPrivateAddresses = true
+
+	status, err := sender.Send(redirect.URL, nil, []byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+	assert.Nil(t, receiver.request)
+}
+
+func TestUpdateSubscription_KeepsSecret(t *testing.T) {
+	existing := &entities.WebhookSubscription{SubscriptionID: "s1", URL: "https://partner/old", Secret: webhookSecret, Active: true}
+	webhookGateway := new(mocks.WebhookGateway)
+	webhookGateway.On("GetSubscription", "s1").Return(existing, nil)
+	webhookGateway.On("SaveSubscription", existing).Return(nil)
+	useCase := newWebhookUseCase(webhookGateway)
+
+	updated, err := useCase.UpdateSubscription("s1", &entities.WebhookSubscription{URL: "https://partner/new", Active: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://partner/new", updated.URL)
+	assert.Equal(t, webhookSecret, updated.Secret)
+}
+
+func TestRelay_DispatchesWebhooksBeforePublishing(t *testing.T) {
+	outboxGateway := obg.NewMemoryGateway()
+	event := entities.OrderEvent{EventID: "e1", Type: entities.OrderStatusChangedEvent}
+	outboxGateway.Save(&entities.OutboxEvent{EventID: "e1", Event: event, Status: entities.PendingOutboxStatus})
+	webhookUseCase := new(mocks.WebhookUseCase)
+	webhookUseCase.On("Dispatch", &event).Return(errors.New("webhooks unavailable")).Once()
+	webhookUseCase.On("Dispatch", &event).Return(nil)
+	queueGateway := new(mocks.QueueGateway)
+	queueGateway.On("Publish", &event).Return(nil)
+	relay := outbox.NewRelay(outboxGateway, queueGateway)
+	relay.Webhooks = webhookUseCase
+
+	// The event is not published while its webhooks cannot be queued
+	assert.NoError(t, relay.PublishPending())
+	queueGateway.AssertNotCalled(t, "Publish", mock.Anything)
+	pending, _ := outboxGateway.GetPending(time.Now().Add(time.Hour), 10)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "webhooks unavailable", pending[0].LastError)
+	}
+
+	assert.NoError(t, relay.PublishPending())
+	pending, _ = outboxGateway.GetPending(time.Now().Add(time.Hour), 10)
+	assert.Len(t, pending, 1, "the retry waits for its backoff")
+	relay.Clock = func() time.Time { return time.Now().Add(time.Hour) }
+	assert.NoError(t, relay.PublishPending())
+
+	pending, _ = outboxGateway.GetPending(time.Now().Add(time.Hour), 10)
+	assert.Empty(t, pending)
+	queueGateway.AssertNumberOfCalls(t, "Publish", 1)
+	webhookUseCase.AssertNumberOfCalls(t, "Dispatch", 2)
+}
+
+func TestRelay_RetriesDoNotDuplicateWebhookDeliveries(t *testing.T) {
+	outboxGateway := obg.NewMemoryGateway()
+	event := entities.OrderEvent{EventID: "e1", Type: entities.OrderStatusChangedEvent,
+		Data: entities.OrderEventData{Order: entities.Order{OrderID: "1", Status: entities.ReadyOrderStatus}}}
+	outboxGateway.Save(&entities.OutboxEvent{EventID: "e1", Event: event, Status: entities.PendingOutboxStatus})
+	webhookGateway := whg.NewMemoryGateway()
+	webhookGateway.SaveSubscription(&entities.WebhookSubscription{SubscriptionID: "s1", Active: true})
+	queueGateway := new(mocks.QueueGateway)
+	queueGateway.On("Publish", mock.Anything).Return(errors.New("queue unavailable")).Once()
+	queueGateway.On("Publish", mock.Anything).Return(nil)
+	relay := outbox.NewRelay(outboxGateway, queueGateway)
+	relay.Webhooks = webhook.NewUseCase(webhookGateway, whs.NewSender())
+
+	assert.NoError(t, relay.PublishPending())
+	relay.Clock = func() time.Time { return time.Now().Add(time.Hour) }
+	assert.NoError(t, relay.PublishPending())
+
+	queueGateway.AssertNumberOfCalls(t, "Publish", 2)
+	deliveries, _ := webhookGateway.GetDeliveriesBySubscription("s1", 10)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, "e1", deliveries[0].EventID)
+	}
+}
+
+func serveWebhooks(useCase *mocks.WebhookUseCase, req *http.Request) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	c := chi.NewRouter()
+	controllers.NewWebhookController(useCase, c)
+	c.ServeHTTP(res, req)
+	return res
+}
+
+func TestPOSTWebhook_DoesNotReturnSecret(t *testing.T) {
+	useCase := new(mocks.WebhookUseCase)
+	useCase.On("CreateSubscription", &entities.WebhookSubscription{
+		URL:        "https://partner/hook",
+		Secret:     webhookSecret,
+		EventTypes: []entities.EventType{entities.OrderStatusChangedEvent},
+		Active:     true,
+	}).Return(&entities.WebhookSubscription{
+		SubscriptionID: "s1",
+		URL:            "https://partner/hook",
+		Secret:         webhookSecret,
+		EventTypes:     []entities.EventType{entities.OrderStatusChangedEvent},
+		Active:         true,
+	}, nil)
+
+	req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(
+		`{"url": "https://partner/hook", "secret": "`+webhookSecret+`", "event_types": ["OrderStatusChanged"]}`))
+	res := serveWebhooks(useCase, req)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Contains(t, res.Body.String(), `"subscription_id":"s1"`)
+	assert.NotContains(t, res.Body.String(), webhookSecret)
+}
+
+func TestGETWebhookDeliveries(t *testing.T) {
+	useCase := new(mocks.WebhookUseCase)
+	useCase.On("ListDeliveries", "s1", 10).Return([]entities.WebhookDelivery{pendingDelivery()}, nil)
+
+	req, _ := http.NewRequest("GET", "/webhooks/s1/deliveries?limit=10", nil)
+	res := serveWebhooks(useCase, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"delivery_id":"d1"`)
+	assert.Contains(t, res.Body.String(), `"status":"PENDING"`)
+}
+
+func TestPOSTRedeliver_NotFound(t *testing.T) {
+	useCase := new(mocks.WebhookUseCase)
+	useCase.On("Redeliver", "s1", "d1").Return(nil, util.NewNotFoundError("delivery_not_found", "Webhook delivery d1 not found"))
+
+	req, _ := http.NewRequest("POST", "/webhooks/s1/deliveries/d1/redeliver", nil)
+	res := serveWebhooks(useCase, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestGETWebhook_NotFound(t *testing.T) {
+	useCase := new(mocks.WebhookUseCase)
+	useCase.On("GetSubscription", "s1").Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/webhooks/s1", nil)
+	res := serveWebhooks(useCase, req)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
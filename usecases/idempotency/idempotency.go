@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+// DefaultTTL is how long a response is kept for replays
+const DefaultTTL = 24 * time.Hour
+
+// DefaultLockTimeout is how long a key stays reserved for a request that has not completed,
+// well over the time an order takes to be created
+const DefaultLockTimeout = 30 * time.Second
+
+type UseCase struct {
+	gateway     interfaces.IdempotencyGatewayI
+	TTL         time.Duration
+	LockTimeout time.Duration
+	Clock       func() time.Time
+}
+
+func NewUseCase(gateway interfaces.IdempotencyGatewayI) UseCase {
+	return UseCase{
+		gateway:     gateway,
+		TTL:         DefaultTTL,
+		LockTimeout: DefaultLockTimeout,
+		Clock:       time.Now,
+	}
+}
+
+// Start returns the record of the client key. A completed record holds the response to replay,
+// otherwise the key was just reserved for this request, which must be completed or released.
+// Reusing a key with another request is a domain error, and retrying while the first request runs is a conflict.
+// A request that did not complete within LockTimeout is presumed dead, and a retry takes its key over.
+func (u UseCase) Start(clientID, key string, request []byte) (*entities.IdempotencyRecord, error) {
+	now := u.Clock()
+	record := &entities.IdempotencyRecord{
+		Key:         clientID + "#" + key,
+		RequestHash: hashRequest(request),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(u.TTL).Unix(),
+		LockedUntil: now.Add(u.LockTimeout).Unix(),
+	}
+
+	created, err := u.gateway.Create(record, now)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return record, nil
+	}
+
+	stored, err := u.gateway.GetByKey(record.Key)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.IsExpired(now) {
+		return nil, util.NewConflictError("idempotency_key_in_use",
+			fmt.Sprintf("Idempotency-Key %s is being released, retry the request", key))
+	}
+	if stored.RequestHash != record.RequestHash {
+		return nil, util.NewValidationError("idempotency_key_reused",
+			fmt.Sprintf("Idempotency-Key %s was already used with a different request", key))
+	}
+	if stored.IsCompleted() {
+		return stored, nil
+	}
+
+	if !stored.IsLocked(now) {
+		takenOver, err := u.gateway.TakeOver(record, stored.LockedUntil)
+		if err != nil {
+			return nil, err
+		}
+		if takenOver {
+			return record, nil
+		}
+	}
+	return nil, util.NewConflictError("idempotency_key_in_use",
+		fmt.Sprintf("A request with Idempotency-Key %s is still being processed", key))
+}
+
+// Complete stores the response of the request that reserved the key, along with its Content-Type. A request that outlived its lock and
+// whose key was taken over by a retry no longer holds the key: its response is not stored, it is a conflict.
+func (u UseCase) Complete(record *entities.IdempotencyRecord, statusCode int, contentType string, response []byte) error {
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Response = string(response)
+	completed, err := u.gateway.Complete(record)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return lostKey(record)
+	}
+	return nil
+}
+
+// Release frees the key of a request that failed unexpectedly, so the client can retry it.
+// A key taken over by a retry is left to the retry, that is a conflict.
+func (u UseCase) Release(record *entities.IdempotencyRecord) error {
+	released, err := u.gateway.Release(record)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return lostKey(record)
+	}
+	return nil
+}
+
+func lostKey(record *entities.IdempotencyRecord) error {
+	return util.NewConflictError("idempotency_key_taken_over",
+		fmt.Sprintf("Idempotency key %s was taken over by a retry after its lock expired", record.Key))
+}
+
+func hashRequest(request []byte) string {
+	hash := sha256.Sum256(request)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,130 @@
+package item
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+type UseCase struct {
+	itemGateway interfaces.ItemGatewayI
+}
+
+func NewUseCase(itemGateway interfaces.ItemGatewayI) UseCase {
+	return UseCase{
+		itemGateway: itemGateway,
+	}
+}
+
+func (i UseCase) List() (*[]entities.Item, error) {
+	items, err := i.itemGateway.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	activeItems := []entities.Item{}
+	if items != nil {
+		for _, item := range *items {
+			if !item.IsDeleted() {
+				activeItems = append(activeItems, item)
+			}
+		}
+	}
+
+	return &activeItems, nil
+}
+
+func (i UseCase) Create(item *entities.Item) (*entities.Item, error) {
+	if err := validateItem(item); err != nil {
+		return nil, err
+	}
+
+	item.ItemID = uuid.New().String()
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Time{}
+	item.DeletedAt = time.Time{}
+
+	itemCreated, err := i.itemGateway.Save(item)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Item %s Criado!\n", item.ItemID)
+
+	return itemCreated, nil
+}
+
+// GetByID returns nil when the item does not exist or was deleted
+func (i UseCase) GetByID(itemID string) (*entities.Item, error) {
+	item, err := i.itemGateway.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if item == nil || item.IsDeleted() {
+		return nil, nil
+	}
+
+	return item, nil
+}
+
+func (i UseCase) Update(itemID string, updatedItem *entities.Item) (*entities.Item, error) {
+	item, err := i.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if item == nil {
+		return nil, nil
+	}
+
+	if err := validateItem(updatedItem); err != nil {
+		return nil, err
+	}
+
+	item.Name = updatedItem.Name
+	item.Category = updatedItem.Category
+	item.Description = updatedItem.Description
+	item.Price = updatedItem.Price
+	item.UpdatedAt = time.Now()
+
+	return i.itemGateway.Save(item)
+}
+
+// Delete marks the item as deleted, so orders already placed keep referencing it
+func (i UseCase) Delete(itemID string) error {
+	item, err := i.GetByID(itemID)
+	if err != nil {
+		return err
+	}
+
+	if item == nil {
+		return util.NewNotFoundError("item_not_found", fmt.Sprintf("Item ID %s not found", itemID))
+	}
+
+	item.DeletedAt = time.Now()
+	_, err = i.itemGateway.Save(item)
+	return err
+}
+
+// validateItem returns every violation found in the item as a single domain error, nil when there are none
+func validateItem(item *entities.Item) error {
+	var fields []util.FieldError
+
+	if item.Name == "" {
+		fields = append(fields, util.FieldError{Field: "name", Message: "must not be empty"})
+	}
+
+	if item.Price <= 0 {
+		fields = append(fields, util.FieldError{Field: "price", Message: "must be greater than zero"})
+	}
+
+	if len(fields) > 0 {
+		return util.NewValidationError("invalid_item", fmt.Sprintf("Item has %d invalid field(s)", len(fields)), fields...)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package order
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+// kitchenPriorities are the statuses of the kitchen queue, by priority: orders already being prepared
+// come before the orders waiting to be claimed
+var kitchenPriorities = map[entities.Status]int{
+	entities.CookingOrderStatus:  0,
+	entities.ReceivedOrderStatus: 1,
+}
+
+// KitchenQueue returns the received and in preparation orders, by priority and then by arrival in the kitchen,
+// along with the quantity of every item they ask for
+func (o UseCase) KitchenQueue() (*entities.KitchenQueue, error) {
+	filter := entities.OrderFilter{
+		Statuses: []entities.Status{entities.CookingOrderStatus, entities.ReceivedOrderStatus},
+		Limit:    maxPageSize,
+	}
+
+	var orders []entities.Order
+	for {
+		page, err := o.orderGateway.GetAll(filter)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page.Orders...)
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	now := o.now()
+	queue := &entities.KitchenQueue{Orders: []entities.KitchenOrder{}, Items: []entities.KitchenItem{}}
+	for _, order := range orders {
+		receivedAt := order.ReceivedAt()
+		queue.Orders = append(queue.Orders, entities.KitchenOrder{
+			Order:      order,
+			ReceivedAt: receivedAt,
+			Elapsed:    now.Sub(receivedAt),
+		})
+	}
+
+	sort.SliceStable(queue.Orders, func(i, j int) bool {
+		a, b := queue.Orders[i], queue.Orders[j]
+		if pa, pb := kitchenPriorities[a.Order.Status], kitchenPriorities[b.Order.Status]; pa != pb {
+			return pa < pb
+		}
+		return a.ReceivedAt.Before(b.ReceivedAt)
+	})
+
+	queue.Items = kitchenItems(queue.Orders)
+	return queue, nil
+}
+
+// kitchenItems adds up the ordered items of the queued orders, the most requested items first
+func kitchenItems(orders []entities.KitchenOrder) []entities.KitchenItem {
+	items := []entities.KitchenItem{}
+	positions := map[string]int{}
+	for _, queued := range orders {
+		counted := map[string]bool{}
+		for _, orderedItem := range queued.Order.OrderedItems {
+			position, ok := positions[orderedItem.ItemID]
+			if !ok {
+				position = len(items)
+				positions[orderedItem.ItemID] = position
+				items = append(items, entities.KitchenItem{ItemID: orderedItem.ItemID, Name: orderedItem.Name})
+			}
+
+			items[position].Quantity += orderedItem.Quantity
+			if !counted[orderedItem.ItemID] {
+				counted[orderedItem.ItemID] = true
+				items[position].Orders++
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Quantity != items[j].Quantity {
+			return items[i].Quantity > items[j].Quantity
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// Claim assigns a queued order to a kitchen station. A received order starts being prepared when it is claimed.
+// Claiming an order again from the same station changes nothing.
+func (o UseCase) Claim(orderID, station, actor string, version int) (*entities.Order, error) {
+	station = strings.TrimSpace(station)
+	if station == "" {
+		return nil, util.NewValidationError("station_required", "A station is required to claim an order",
+			util.FieldError{Field: "station", Message: "must not be empty"})
+	}
+
+	order, err := o.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, nil
+	}
+
+	if err := checkVersion(order, version); err != nil {
+		return nil, err
+	}
+
+	if order.Station == station {
+		return order, nil
+	}
+
+	if order.IsClaimed() {
+		return nil, util.NewConflictError("order_already_claimed",
+			fmt.Sprintf("Order %s was already claimed by station %s", order.OrderID, order.Station))
+	}
+
+	if _, queued := kitchenPriorities[order.Status]; !queued {
+		return nil, util.NewInvalidTransitionError("claim_not_allowed",
+			fmt.Sprintf("Order %s is %s and is not in the kitchen queue", order.OrderID, order.Status))
+	}
+
+	var now = o.now()
+	order.Station = station
+	order.ClaimedAt = &now
+	order.UpdatedAt = now
+	orderFields := []entities.OrderField{entities.OrderClaimField}
+
+	statusChanged := order.Status == entities.ReceivedOrderStatus
+	if statusChanged {
+		order.ChangeStatus(entities.CookingOrderStatus, actor, fmt.Sprintf("claimed by station %s", station), now)
+		orderFields = append(orderFields, entities.OrderStatusField)
+	}
+
+	o.addEvent(order, entities.OrderClaimedEvent, nil)
+	if statusChanged {
+		o.addStatusChangedEvent(order)
+	}
+
+	log.Printf("Pedido %s assumido pela estação %s\n", order.OrderID, station)
+	return o.update(orderID, order, orderFields...)
+}
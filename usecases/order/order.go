@@ -6,93 +6,181 @@ import (
 	"github.com/postech-soat2-grupo16/pedidos-api/entities"
 	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
 	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"golang.org/x/exp/slices"
 	"log"
+	"strings"
 	"time"
 )
 
 type UseCase struct {
 	orderGateway interfaces.OrderGatewayI
-	queueGateway interfaces.QueueGatewayI
+	itemGateway  interfaces.ItemGatewayI
+	// Clock returns the current time, tests replace it to get predictable timestamps
+	Clock func() time.Time
+	// Broadcaster receives the events of every stored change, for the order streams. Nil disables streaming.
+	Broadcaster interfaces.OrderBroadcasterI
 }
 
-func NewUseCase(orderGateway interfaces.OrderGatewayI, queueGateway interfaces.QueueGatewayI) UseCase {
+func NewUseCase(orderGateway interfaces.OrderGatewayI, itemGateway interfaces.ItemGatewayI) UseCase {
 	return UseCase{
 		orderGateway: orderGateway,
-		queueGateway: queueGateway,
+		itemGateway:  itemGateway,
+		Clock:        time.Now,
 	}
 }
 
-func (o UseCase) List(clientID, status string) (orders *[]entities.Order, err error) {
+// now is the current time in UTC
+func (o UseCase) now() time.Time {
+	return o.Clock().UTC()
+}
 
-	if clientID == "" {
-		orders, err = o.getAllOrders()
-	} else {
-		orders, err = o.getAllOrdersByClientID(clientID)
-	}
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
 
-	if status != "" {
-		orders, err = o.filterOrdersByStatus(status, orders)
+// List pages through the orders matching the filter, soft deleted orders are only listed when IncludeDeleted is set
+func (o UseCase) List(filter entities.OrderFilter) (*entities.OrderPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
 	}
 
-	return orders, nil
-}
-
-func (o UseCase) getAllOrders() (orders *[]entities.Order, err error) {
-	orders, err = o.orderGateway.GetAll()
+	statuses, err := validStatuses(filter.Statuses)
 	if err != nil {
 		return nil, err
 	}
+	filter.Statuses = statuses
 
-	return orders, nil
-}
-
-func (o UseCase) getAllOrdersByClientID(clientID string) (orders *[]entities.Order, err error) {
-	orders, err = o.orderGateway.GetAllByClientID(clientID)
-	if err != nil {
-		return nil, err
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && filter.CreatedFrom.After(filter.CreatedTo) {
+		return nil, util.NewBadRequestError("invalid_parameter", "created_from must not be after created_to",
+			util.FieldError{Field: "created_to", Message: "must not be before created_from"})
 	}
 
-	return orders, nil
+	return o.orderGateway.GetAll(filter)
 }
 
-func (o UseCase) filterOrdersByStatus(status string, orders *[]entities.Order) (*[]entities.Order, error) {
-	if orders != nil {
-		var filteredOrders []entities.Order
-		for _, order := range *orders {
-			if string(order.Status) == status {
-				filteredOrders = append(filteredOrders, order)
-			}
+// validStatuses removes the repeated statuses of a filter, failing on statuses outside the order lifecycle
+func validStatuses(statuses []entities.Status) ([]entities.Status, error) {
+	var valid []entities.Status
+	for _, status := range statuses {
+		if !status.IsValid() {
+			return nil, util.NewBadRequestError("invalid_parameter", fmt.Sprintf("Invalid status %s", status),
+				util.FieldError{Field: "status", Message: "must be a valid order status"})
+		}
+		if !slices.Contains(valid, status) {
+			valid = append(valid, status)
 		}
-		return &filteredOrders, nil
 	}
-	return nil, nil
+	return valid, nil
 }
 
 func (o UseCase) Create(order *entities.Order) (*entities.Order, error) {
-	var now = time.Now().String()
+	// Every order starts as CRIADO, the client may only send that status or none
+	if order.Status == "" {
+		order.Status = entities.CreatedOrdersStatus
+	}
+
+	fields := validateOrder(order, true)
+	if strings.TrimSpace(order.ClientID) == "" {
+		fields = append(fields, util.FieldError{Field: "client_id", Message: "is required"})
+	}
+
+	catalogFields, err := o.resolveOrderedItems(order.OrderedItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validationError(append(fields, catalogFields...)); err != nil {
+		return nil, err
+	}
+
+	// Discounts are only granted by the server and there is no discount rule yet
+	order.Discount = 0
+	if err := calculateTotals(order); err != nil {
+		return nil, err
+	}
+
+	var now = o.now()
 
 	order.OrderID = uuid.New().String()
 	order.CreatedAt = now
-	order.UpdatedAt = ""
-	var orderCreated, err = o.orderGateway.Save(order)
+	order.UpdatedAt = now
+	order.StatusHistory = []entities.StatusChange{{
+		To:        order.Status,
+		ChangedAt: now,
+		Actor:     order.ClientID,
+		Reason:    "order created",
+	}}
+	o.addEvent(order, entities.OrderCreatedEvent, nil)
+
+	orderCreated, err := o.save(order)
 	if err != nil {
 		return nil, err
 	}
 	log.Printf("Pedido %s Criado!\n", order.OrderID)
 
-	return o.queueGateway.SendMessage(orderCreated)
+	return orderCreated, nil
+}
+
+// addEvent queues an event carrying the order as it will be once saved.
+// The gateway writes it to the outbox together with the order, and the outbox relay publishes it on the queue.
+func (o UseCase) addEvent(order *entities.Order, eventType entities.EventType, statusChange *entities.StatusChange) {
+	snapshot := *order
+	snapshot.Version = order.Version + 1
+	snapshot.PendingEvents = nil
+
+	now := o.now()
+	eventID := uuid.New().String()
+	order.PendingEvents = append(order.PendingEvents, entities.OutboxEvent{
+		EventID: eventID,
+		OrderID: order.OrderID,
+		Event: entities.OrderEvent{
+			EventID:       eventID,
+			Type:          eventType,
+			SchemaVersion: entities.OrderEventSchemaVersion,
+			OccurredAt:    now,
+			CorrelationID: order.OrderID,
+			Data: entities.OrderEventData{
+				Order:        snapshot,
+				StatusChange: statusChange,
+			},
+		},
+		Status:        entities.PendingOutboxStatus,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+}
+
+// addStatusChangedEvent queues the event of the last status change, a cancellation has its own event type
+func (o UseCase) addStatusChangedEvent(order *entities.Order) {
+	change := order.StatusHistory[len(order.StatusHistory)-1]
+	eventType := entities.OrderStatusChangedEvent
+	if change.To == entities.CanceledOrderStatus {
+		eventType = entities.OrderCancelledEvent
+	}
+	o.addEvent(order, eventType, &change)
 }
 
+// GetByID returns nil for missing and soft deleted orders
 func (o UseCase) GetByID(orderID string) (*entities.Order, error) {
 	order, err := o.orderGateway.GetByID(orderID)
 	if err != nil {
 		return nil, err
 	}
 
+	if order == nil || order.IsDeleted() {
+		return nil, nil
+	}
+
 	return order, nil
 }
 
-func (o UseCase) Update(orderID string, updatedOrder *entities.Order) (*entities.Order, error) {
+// Update changes the items, notes and status of an order. Items and notes only change in the editable statuses
+// and a change to CANCELADO is a cancellation, which requires a reason and no other change.
+func (o UseCase) Update(orderID string, updatedOrder *entities.Order, actor, reason string) (*entities.Order, error) {
 	order, err := o.GetByID(orderID)
 	if err != nil {
 		return nil, err
@@ -102,20 +190,77 @@ func (o UseCase) Update(orderID string, updatedOrder *entities.Order) (*entities
 		return nil, nil
 	}
 
-	if !updatedOrder.IsStatusValid() {
-		return nil, util.NewErrorDomain(fmt.Sprintf("Status %s is not valid", updatedOrder.Status))
+	if err := checkVersion(order, updatedOrder.Version); err != nil {
+		return nil, err
 	}
 
-	var now = time.Now().String()
-	order.OrderedItems = updatedOrder.OrderedItems
-	order.Status = updatedOrder.Status
-	order.Notes = updatedOrder.Notes
+	fields := validateOrder(updatedOrder, false)
+	contentChanged := !sameContent(order, updatedOrder)
+	if contentChanged {
+		catalogFields, err := o.resolveOrderedItems(updatedOrder.OrderedItems)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, catalogFields...)
+	}
+
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+
+	if err := checkStatusTransition(order, updatedOrder.Status); err != nil {
+		return nil, err
+	}
+
+	if contentChanged && !order.Status.IsEditable() {
+		return nil, util.NewInvalidTransitionError("order_not_editable",
+			fmt.Sprintf("Order %s is %s and its items and notes can no longer be changed", order.OrderID, order.Status))
+	}
+
+	if updatedOrder.Status == entities.CanceledOrderStatus && order.Status != entities.CanceledOrderStatus {
+		if contentChanged {
+			return nil, util.NewValidationError("cancellation_with_changes",
+				"An order cannot be changed and cancelled at once",
+				util.FieldError{Field: "status", Message: "cannot be CANCELADO while the items or notes change"})
+		}
+		if err := checkCancellationReason(reason); err != nil {
+			return nil, err
+		}
+		return o.cancel(order, actor, reason)
+	}
+
+	statusChanged := order.Status != updatedOrder.Status
+	orderFields := []entities.OrderField{entities.OrderItemsField, entities.OrderNotesField}
+	if statusChanged {
+		orderFields = append(orderFields, entities.OrderStatusField)
+	}
+
+	// The stored discount is kept, clients cannot change it
+	if contentChanged {
+		order.OrderedItems = updatedOrder.OrderedItems
+		order.Notes = updatedOrder.Notes
+		if err := calculateTotals(order); err != nil {
+			return nil, err
+		}
+	}
+
+	if reason == "" {
+		reason = "order updated"
+	}
+	var now = o.now()
+	order.ChangeStatus(updatedOrder.Status, actor, reason, now)
 	order.UpdatedAt = now
 
-	return o.orderGateway.Save(order)
+	o.addEvent(order, entities.OrderUpdatedEvent, nil)
+	if statusChanged {
+		o.addStatusChangedEvent(order)
+	}
+
+	return o.update(orderID, order, orderFields...)
 }
 
-func (o UseCase) UpdateOrderStatus(orderID string, orderStatus entities.Status) (*entities.Order, error) {
+func (o UseCase) UpdateOrderStatus(orderID string, orderStatus entities.Status, actor, reason string,
+	version int) (*entities.Order, error) {
 	order, err := o.GetByID(orderID)
 	if err != nil {
 		return nil, err
@@ -125,24 +270,216 @@ func (o UseCase) UpdateOrderStatus(orderID string, orderStatus entities.Status)
 		return nil, nil
 	}
 
-	order.Status = orderStatus
-	if !order.IsStatusValid() {
-		return order, util.NewErrorDomain(fmt.Sprintf("Status %s is not valid", orderStatus))
+	if err := checkVersion(order, version); err != nil {
+		return nil, err
+	}
+
+	if err := checkStatusTransition(order, orderStatus); err != nil {
+		return order, err
+	}
+
+	if order.Status == orderStatus {
+		return order, nil
 	}
 
+	var now = o.now()
+	order.ChangeStatus(orderStatus, actor, reason, now)
+	order.UpdatedAt = now
+	o.addStatusChangedEvent(order)
+
 	log.Printf("Pedido %s patched. Novo Status: %s\n", order.OrderID, order.Status)
-	return o.orderGateway.Save(order)
+	return o.update(orderID, order, entities.OrderStatusField)
+}
+
+// Cancel cancels an order that has not started being prepared yet. The cancellation event lets payments refund the client.
+func (o UseCase) Cancel(orderID, actor, reason string, version int) (*entities.Order, error) {
+	if err := checkCancellationReason(reason); err != nil {
+		return nil, err
+	}
+
+	order, err := o.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, nil
+	}
+
+	if err := checkVersion(order, version); err != nil {
+		return nil, err
+	}
+
+	return o.cancel(order, actor, reason)
+}
+
+// cancel cancels an order whose version was already checked, for Cancel and Update
+func (o UseCase) cancel(order *entities.Order, actor, reason string) (*entities.Order, error) {
+	if order.Status == entities.CanceledOrderStatus {
+		return order, nil
+	}
+
+	if !order.CanTransitionTo(entities.CanceledOrderStatus) {
+		return nil, util.NewInvalidTransitionError("cancellation_not_allowed",
+			fmt.Sprintf("Order %s is %s and can no longer be cancelled", order.OrderID, order.Status))
+	}
+
+	var now = o.now()
+	order.ChangeStatus(entities.CanceledOrderStatus, actor, reason, now)
+	order.UpdatedAt = now
+	o.addStatusChangedEvent(order)
+
+	log.Printf("Pedido %s cancelado. Motivo: %s\n", order.OrderID, reason)
+	return o.update(order.OrderID, order, entities.OrderStatusField)
 }
 
-func (o UseCase) Delete(orderID string) error {
+func checkCancellationReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return util.NewValidationError("reason_required", "A reason is required to cancel an order",
+			util.FieldError{Field: "reason", Message: "must not be empty"})
+	}
+	return nil
+}
+
+// resolveOrderedItems overwrites name, category, description and price of every ordered item with the catalog data.
+// It returns a violation for every item that cannot be ordered from the catalog.
+func (o UseCase) resolveOrderedItems(orderedItems []entities.OrderedItem) ([]util.FieldError, error) {
+	var fields []util.FieldError
+	for i := range orderedItems {
+		orderedItem := &orderedItems[i]
+		if strings.TrimSpace(orderedItem.ItemID) == "" {
+			continue
+		}
+
+		item, err := o.itemGateway.GetByID(orderedItem.ItemID)
+		if err != nil {
+			return nil, err
+		}
+
+		if item == nil || item.IsDeleted() {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("ordered_items[%d].item_id", i),
+				Message: fmt.Sprintf("item %s not found in the catalog", orderedItem.ItemID)})
+			continue
+		}
+
+		if item.Price <= 0 {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("ordered_items[%d].price", i),
+				Message: "catalog price must be greater than zero"})
+		}
+
+		orderedItem.Name = item.Name
+		orderedItem.Category = string(item.Category)
+		orderedItem.Description = item.Description
+		orderedItem.Price = item.Price
+	}
+
+	return fields, nil
+}
+
+// sameContent reports whether an update keeps the ordered items, with their quantities, and the notes of the order
+func sameContent(order, updatedOrder *entities.Order) bool {
+	if order.Notes != updatedOrder.Notes || len(order.OrderedItems) != len(updatedOrder.OrderedItems) {
+		return false
+	}
+	for i, orderedItem := range order.OrderedItems {
+		updatedItem := updatedOrder.OrderedItems[i]
+		if orderedItem.ItemID != updatedItem.ItemID || orderedItem.Quantity != updatedItem.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// checkVersion fails when the client based its change on another version of the order. Version 0 skips the check.
+func checkVersion(order *entities.Order, version int) error {
+	if version != 0 && version != order.Version {
+		return util.NewConflictError("version_mismatch",
+			fmt.Sprintf("Order %s is at version %d, not %d", order.OrderID, order.Version, version))
+	}
+
+	return nil
+}
+
+// calculateTotals fails when the discount, already known not to be negative, is greater than the subtotal
+func calculateTotals(order *entities.Order) error {
+	order.CalculateTotals()
+	if order.Total < 0 {
+		return util.NewValidationError("invalid_discount",
+			fmt.Sprintf("Discount %d is greater than the order subtotal %d", order.Discount, order.Subtotal),
+			util.FieldError{Field: "discount_cents", Message: "must not be greater than the subtotal"})
+	}
+
+	return nil
+}
+
+func checkStatusTransition(order *entities.Order, status entities.Status) error {
+	if !status.IsValid() {
+		return util.NewValidationError("invalid_status", fmt.Sprintf("Status %s is not valid", status),
+			util.FieldError{Field: "status", Message: "is not an order status"})
+	}
+
+	if !order.CanTransitionTo(status) {
+		var allowed []string
+		for _, next := range order.NextStatuses() {
+			allowed = append(allowed, string(next))
+		}
+		if len(allowed) == 0 {
+			allowed = append(allowed, "none")
+		}
+		return util.NewInvalidTransitionError("transition_not_allowed", fmt.Sprintf(
+			"Transition from %s to %s is not allowed. Allowed next statuses: %s",
+			order.Status, status, strings.Join(allowed, ", ")))
+	}
+
+	return nil
+}
+
+// Delete soft deletes the order: it is kept, marked with who deleted it and when, and can be restored
+func (o UseCase) Delete(orderID, actor string) error {
 	order, err := o.GetByID(orderID)
 	if err != nil {
 		return err
 	}
 
 	if order == nil {
-		return util.NewErrorDomain(fmt.Sprintf("Order ID %s not found", orderID))
+		return util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID))
 	}
 
-	return o.orderGateway.Delete(order)
+	var now = o.now()
+	order.DeletedAt = &now
+	order.DeletedBy = actor
+	order.UpdatedAt = now
+	o.addEvent(order, entities.OrderDeletedEvent, nil)
+
+	orderDeleted, err := o.update(orderID, order, entities.OrderDeletionField)
+	if err != nil {
+		return err
+	}
+
+	if orderDeleted == nil {
+		return util.NewNotFoundError("order_not_found", fmt.Sprintf("Order ID %s not found", orderID))
+	}
+
+	log.Printf("Pedido %s removido por %s\n", orderID, actor)
+	return nil
+}
+
+// Restore brings back a soft deleted order. Restoring an order that is not deleted changes nothing.
+func (o UseCase) Restore(orderID, actor string) (*entities.Order, error) {
+	order, err := o.orderGateway.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil || !order.IsDeleted() {
+		return order, nil
+	}
+
+	order.DeletedAt = nil
+	order.DeletedBy = ""
+	order.UpdatedAt = o.now()
+	o.addEvent(order, entities.OrderRestoredEvent, nil)
+
+	log.Printf("Pedido %s restaurado por %s\n", orderID, actor)
+	return o.update(orderID, order, entities.OrderDeletionField)
 }
@@ -0,0 +1,36 @@
+package order
+
+import (
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// save stores a new order, then publishes its events
+func (o UseCase) save(order *entities.Order) (*entities.Order, error) {
+	events := order.PendingEvents
+	orderSaved, err := o.orderGateway.Save(order)
+	if err == nil && orderSaved != nil {
+		o.publish(events)
+	}
+	return orderSaved, err
+}
+
+// update stores the changed fields of an order, then publishes its events
+func (o UseCase) update(orderID string, order *entities.Order, fields ...entities.OrderField) (*entities.Order, error) {
+	events := order.PendingEvents
+	orderUpdated, err := o.orderGateway.Update(orderID, order, fields...)
+	if err == nil && orderUpdated != nil {
+		o.publish(events)
+	}
+	return orderUpdated, err
+}
+
+// publish sends the events of a stored change to the order streams. The outbox relay sends them to the queue
+// and to the webhooks.
+func (o UseCase) publish(events []entities.OutboxEvent) {
+	if o.Broadcaster == nil {
+		return
+	}
+	for i := range events {
+		o.Broadcaster.Publish(events[i].Event)
+	}
+}
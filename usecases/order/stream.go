@@ -0,0 +1,22 @@
+package order
+
+import (
+	"errors"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+)
+
+// Subscribe starts a stream of the order events matching the filter, resuming after lastEventID when it is not zero
+func (o UseCase) Subscribe(filter entities.StreamFilter, lastEventID uint64) (*entities.OrderSubscription, error) {
+	if o.Broadcaster == nil {
+		return nil, errors.New("order streaming is not enabled")
+	}
+
+	statuses, err := validStatuses(filter.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	filter.Statuses = statuses
+
+	return o.Broadcaster.Subscribe(filter, lastEventID), nil
+}
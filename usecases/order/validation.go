@@ -0,0 +1,65 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+)
+
+const (
+	maxOrderedItems = 50
+	maxItemQuantity = 99
+	maxNotesLength  = 500
+)
+
+// validateOrder checks the fields a client may send on an order and returns every violation found.
+// A new order must have the initial status, an existing one a status of the lifecycle.
+func validateOrder(order *entities.Order, isNew bool) []util.FieldError {
+	var fields []util.FieldError
+
+	switch {
+	case isNew && order.Status != entities.CreatedOrdersStatus:
+		fields = append(fields, util.FieldError{Field: "status",
+			Message: fmt.Sprintf("must be %s, new orders start as %s", entities.CreatedOrdersStatus, entities.CreatedOrdersStatus)})
+	case !isNew && !order.Status.IsValid():
+		fields = append(fields, util.FieldError{Field: "status", Message: "is not an order status"})
+	}
+
+	if len(order.OrderedItems) == 0 {
+		fields = append(fields, util.FieldError{Field: "ordered_items", Message: "must have at least one item"})
+	}
+	if len(order.OrderedItems) > maxOrderedItems {
+		fields = append(fields, util.FieldError{Field: "ordered_items",
+			Message: fmt.Sprintf("must have at most %d items", maxOrderedItems)})
+	}
+
+	for i, orderedItem := range order.OrderedItems {
+		if strings.TrimSpace(orderedItem.ItemID) == "" {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("ordered_items[%d].item_id", i),
+				Message: "is required"})
+		}
+		if orderedItem.Quantity < 1 || orderedItem.Quantity > maxItemQuantity {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("ordered_items[%d].quantity", i),
+				Message: fmt.Sprintf("must be between 1 and %d", maxItemQuantity)})
+		}
+	}
+
+	if utf8.RuneCountInString(order.Notes) > maxNotesLength {
+		fields = append(fields, util.FieldError{Field: "notes",
+			Message: fmt.Sprintf("must have at most %d characters", maxNotesLength)})
+	}
+
+	return fields
+}
+
+// validationError joins the violations found in an order into a single domain error, nil when there are none
+func validationError(fields []util.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return util.NewValidationError("invalid_order", fmt.Sprintf("Order has %d invalid field(s)", len(fields)), fields...)
+}
@@ -0,0 +1,118 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+)
+
+const (
+	defaultInterval    = 2 * time.Second
+	defaultBatchSize   = 25
+	defaultMaxAttempts = 10
+	baseBackoff        = time.Second
+	maxBackoff         = 5 * time.Minute
+)
+
+// Relay publishes the events saved in the outbox to the queue and to the webhooks,
+// retrying failures with exponential backoff
+type Relay struct {
+	outboxGateway interfaces.OutboxGatewayI
+	queueGateway  interfaces.QueueGatewayI
+	// Webhooks queues the webhook deliveries of the events. Nil disables webhooks.
+	Webhooks    interfaces.WebhookUseCase
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Clock       func() time.Time
+}
+
+func NewRelay(outboxGateway interfaces.OutboxGatewayI, queueGateway interfaces.QueueGatewayI) *Relay {
+	return &Relay{
+		outboxGateway: outboxGateway,
+		queueGateway:  queueGateway,
+		Interval:      defaultInterval,
+		BatchSize:     defaultBatchSize,
+		MaxAttempts:   defaultMaxAttempts,
+		Clock:         time.Now,
+	}
+}
+
+// Run publishes pending events every Interval until the context is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.PublishPending(); err != nil {
+				log.Printf("Erro ao publicar eventos do outbox: %s\n", err)
+			}
+		}
+	}
+}
+
+// PublishPending publishes one batch of pending events whose retry time has come.
+// Events waiting for their retry are left out of the batch, so they do not hold back the newer ones.
+func (r *Relay) PublishPending() error {
+	now := r.Clock()
+	events, err := r.outboxGateway.GetPending(now, r.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		event := &events[i]
+		r.publish(event, now)
+		if err := r.outboxGateway.Save(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) publish(event *entities.OutboxEvent, now time.Time) {
+	event.Attempts++
+
+	err := r.deliver(&event.Event)
+	if err == nil {
+		event.Status = entities.SentOutboxStatus
+		event.SentAt = now
+		event.LastError = ""
+		return
+	}
+
+	log.Printf("Erro ao publicar evento %s (tentativa %d): %s\n", event.EventID, event.Attempts, err)
+	event.LastError = err.Error()
+	if event.Attempts >= r.MaxAttempts {
+		event.Status = entities.FailedOutboxStatus
+		return
+	}
+	event.NextAttemptAt = now.Add(backoff(event.Attempts))
+}
+
+// deliver queues the webhook deliveries before publishing to the queue: queuing them again when the event
+// is retried does not duplicate them, while publishing again does
+func (r *Relay) deliver(event *entities.OrderEvent) error {
+	if r.Webhooks != nil {
+		if err := r.Webhooks.Dispatch(event); err != nil {
+			return err
+		}
+	}
+	return r.queueGateway.Publish(event)
+}
+
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,394 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/postech-soat2-grupo16/pedidos-api/entities"
+	"github.com/postech-soat2-grupo16/pedidos-api/interfaces"
+	"github.com/postech-soat2-grupo16/pedidos-api/util"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultInterval    = 2 * time.Second
+	defaultBatchSize   = 25
+	defaultMaxAttempts = 8
+	baseBackoff        = 10 * time.Second
+	maxBackoff         = time.Hour
+	minSecretLength    = 16
+	maxDeliveryLog     = 100
+)
+
+// Headers of every delivery. The signature is the hex HMAC-SHA256 of "<timestamp>.<body>" with the subscription secret.
+const (
+	DeliveryHeader  = "X-Webhook-Delivery"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// UseCase manages the webhook subscriptions and delivers the order status changes to them,
+// retrying failed deliveries with exponential backoff
+type UseCase struct {
+	webhookGateway interfaces.WebhookGatewayI
+	sender         interfaces.WebhookSenderI
+	// Clock returns the current time, tests replace it to get predictable timestamps
+	Clock       func() time.Time
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+}
+
+func NewUseCase(webhookGateway interfaces.WebhookGatewayI, sender interfaces.WebhookSenderI) UseCase {
+	return UseCase{
+		webhookGateway: webhookGateway,
+		sender:         sender,
+		Clock:          time.Now,
+		Interval:       defaultInterval,
+		BatchSize:      defaultBatchSize,
+		MaxAttempts:    defaultMaxAttempts,
+	}
+}
+
+func (w UseCase) now() time.Time {
+	return w.Clock().UTC()
+}
+
+func (w UseCase) CreateSubscription(subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	now := w.now()
+	subscription.SubscriptionID = uuid.New().String()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	if err := w.webhookGateway.SaveSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Webhook %s registrado para %s\n", subscription.SubscriptionID, subscription.URL)
+	return subscription, nil
+}
+
+func (w UseCase) GetSubscription(subscriptionID string) (*entities.WebhookSubscription, error) {
+	return w.webhookGateway.GetSubscription(subscriptionID)
+}
+
+func (w UseCase) ListSubscriptions() ([]entities.WebhookSubscription, error) {
+	return w.webhookGateway.GetAllSubscriptions()
+}
+
+// UpdateSubscription replaces the URL, the filters and the active flag. The secret is kept when none is given.
+func (w UseCase) UpdateSubscription(subscriptionID string,
+	updatedSubscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	subscription, err := w.webhookGateway.GetSubscription(subscriptionID)
+	if err != nil || subscription == nil {
+		return nil, err
+	}
+
+	if updatedSubscription.Secret == "" {
+		updatedSubscription.Secret = subscription.Secret
+	}
+	if err := validateSubscription(updatedSubscription); err != nil {
+		return nil, err
+	}
+
+	subscription.URL = updatedSubscription.URL
+	subscription.EventTypes = updatedSubscription.EventTypes
+	subscription.Statuses = updatedSubscription.Statuses
+	subscription.Secret = updatedSubscription.Secret
+	subscription.Active = updatedSubscription.Active
+	subscription.UpdatedAt = w.now()
+
+	if err := w.webhookGateway.SaveSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (w UseCase) DeleteSubscription(subscriptionID string) error {
+	subscription, err := w.webhookGateway.GetSubscription(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if subscription == nil {
+		return util.NewNotFoundError("subscription_not_found", fmt.Sprintf("Webhook subscription %s not found", subscriptionID))
+	}
+
+	log.Printf("Webhook %s removido\n", subscriptionID)
+	return w.webhookGateway.DeleteSubscription(subscriptionID)
+}
+
+func validateSubscription(subscription *entities.WebhookSubscription) error {
+	var fields []util.FieldError
+
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		fields = append(fields, util.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	} else if isInternalHost(target.Hostname()) {
+		fields = append(fields, util.FieldError{Field: "url",
+			Message: "must not point to a loopback, link-local or private address"})
+	}
+
+	if len(subscription.Secret) < minSecretLength {
+		fields = append(fields, util.FieldError{Field: "secret",
+			Message: fmt.Sprintf("must have at least %d characters", minSecretLength)})
+	}
+
+	for i, eventType := range subscription.EventTypes {
+		if !slices.Contains(entities.WebhookEventTypes, eventType) {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("event_types[%d]", i),
+				Message: fmt.Sprintf("%s is not a webhook event", eventType)})
+		}
+	}
+
+	for i, status := range subscription.Statuses {
+		if !status.IsValid() {
+			fields = append(fields, util.FieldError{Field: fmt.Sprintf("statuses[%d]", i),
+				Message: "is not an order status"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return util.NewValidationError("invalid_subscription",
+			fmt.Sprintf("Webhook subscription has %d invalid field(s)", len(fields)), fields...)
+	}
+	return nil
+}
+
+// isInternalHost reports whether the host is a name or an address of the internal network. Names resolving
+// to internal addresses are only known when a delivery is sent, the sender refuses to dial them.
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && !util.IsPublicIP(ip)
+}
+
+// Dispatch queues a delivery of the event to every active subscription accepting it.
+// Events that are not status changes are ignored. Dispatching an event again, e.g. when the outbox relay
+// retries it, only queues the deliveries that are missing.
+func (w UseCase) Dispatch(event *entities.OrderEvent) error {
+	if !slices.Contains(entities.WebhookEventTypes, event.Type) {
+		return nil
+	}
+
+	subscriptions, err := w.webhookGateway.GetAllSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := w.now()
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Accepts(event) {
+			continue
+		}
+
+		delivery := &entities.WebhookDelivery{
+			DeliveryID:     deliveryID(event.EventID, subscription.SubscriptionID),
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        event.EventID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         entities.PendingDeliveryStatus,
+			CreatedAt:      now,
+			NextAttemptAt:  now,
+		}
+		if _, err := w.webhookGateway.CreateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliveryID identifies the delivery of an event to a subscription, so it is queued only once
+func deliveryID(eventID, subscriptionID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(eventID+"/"+subscriptionID)).String()
+}
+
+// ListDeliveries returns the latest deliveries of a subscription, newest first
+func (w UseCase) ListDeliveries(subscriptionID string, limit int) ([]entities.WebhookDelivery, error) {
+	subscription, err := w.webhookGateway.GetSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription == nil {
+		return nil, util.NewNotFoundError("subscription_not_found", fmt.Sprintf("Webhook subscription %s not found", subscriptionID))
+	}
+
+	if limit <= 0 || limit > maxDeliveryLog {
+		limit = maxDeliveryLog
+	}
+
+	return w.webhookGateway.GetDeliveriesBySubscription(subscriptionID, limit)
+}
+
+// Redeliver sends a delivery again right away, whatever its status. When it fails, it is retried
+// as a new delivery would be. Deliveries of inactive subscriptions are not sent again.
+func (w UseCase) Redeliver(subscriptionID, deliveryID string) (*entities.WebhookDelivery, error) {
+	delivery, err := w.webhookGateway.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == nil || delivery.SubscriptionID != subscriptionID {
+		return nil, util.NewNotFoundError("delivery_not_found", fmt.Sprintf("Webhook delivery %s not found", deliveryID))
+	}
+
+	subscription, err := w.webhookGateway.GetSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription == nil {
+		return nil, util.NewNotFoundError("subscription_not_found", fmt.Sprintf("Webhook subscription %s not found", subscriptionID))
+	}
+
+	if !subscription.Active {
+		return nil, util.NewValidationError("subscription_inactive",
+			fmt.Sprintf("Webhook subscription %s is not active, activate it to send its deliveries again", subscriptionID))
+	}
+
+	delivery.Attempts = 0
+	w.deliver(delivery, subscription, w.now())
+	if err := w.webhookGateway.SaveDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Run delivers the pending deliveries every Interval until the context is done
+func (w UseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.DeliverPending(); err != nil {
+				log.Printf("Erro ao entregar webhooks: %s\n", err)
+			}
+		}
+	}
+}
+
+// DeliverPending sends one batch of pending deliveries whose retry time has come. Deliveries waiting
+// for a retry are left out of the batch, so they do not hold back the ones that are due.
+func (w UseCase) DeliverPending() error {
+	now := w.now()
+	deliveries, err := w.webhookGateway.GetPendingDeliveries(now, w.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := map[string]*entities.WebhookSubscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, fetched := subscriptions[delivery.SubscriptionID]
+		if !fetched {
+			if subscription, err = w.webhookGateway.GetSubscription(delivery.SubscriptionID); err != nil {
+				return err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		switch {
+		case subscription == nil:
+			delivery.Status = entities.FailedDeliveryStatus
+			delivery.LastError = "subscription was deleted"
+		case !subscription.Active:
+			delivery.Status = entities.FailedDeliveryStatus
+			delivery.LastError = "subscription is not active"
+		default:
+			w.deliver(delivery, subscription, now)
+		}
+
+		if err := w.webhookGateway.SaveDelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w UseCase) deliver(delivery *entities.WebhookDelivery, subscription *entities.WebhookSubscription, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+
+	timestamp := now.Unix()
+	body := []byte(delivery.Payload)
+	statusCode, err := w.sender.Send(subscription.URL, map[string]string{
+		"Content-Type":  "application/json",
+		DeliveryHeader:  delivery.DeliveryID,
+		EventHeader:     string(delivery.EventType),
+		TimestampHeader: strconv.FormatInt(timestamp, 10),
+		SignatureHeader: Sign(subscription.Secret, timestamp, body),
+	}, body)
+	delivery.ResponseStatus = statusCode
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		delivery.Status = entities.DeliveredDeliveryStatus
+		delivery.DeliveredAt = now
+		delivery.LastError = ""
+		return
+	}
+
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("receiver answered with status %d", statusCode)
+	}
+	log.Printf("Erro ao entregar webhook %s (tentativa %d): %s\n", delivery.DeliveryID, delivery.Attempts, delivery.LastError)
+
+	if delivery.Attempts >= w.MaxAttempts {
+		delivery.Status = entities.FailedDeliveryStatus
+		return
+	}
+	delivery.Status = entities.PendingDeliveryStatus
+	delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts))
+}
+
+// Sign returns the signature header value of a delivery body sent at timestamp (Unix seconds)
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
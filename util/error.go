@@ -2,23 +2,113 @@ package util
 
 import "errors"
 
-type ErrorDomain struct {
-	err     error
+// ErrorCategory tells what kind of domain error happened, so it can be reported without parsing its message
+type ErrorCategory string
+
+const (
+	// BadRequestCategory is a malformed request: body, query parameters or headers that cannot be parsed
+	BadRequestCategory ErrorCategory = "bad_request"
+	// ValidationCategory is a well-formed request with invalid data
+	ValidationCategory ErrorCategory = "validation"
+	// NotFoundCategory is a request about something that does not exist
+	NotFoundCategory ErrorCategory = "not_found"
+	// ConflictCategory is a write based on an outdated version of the data
+	ConflictCategory ErrorCategory = "conflict"
+	// InvalidTransitionCategory is a change the order lifecycle does not allow in the current status
+	InvalidTransitionCategory ErrorCategory = "invalid_transition"
+	// ForbiddenCategory is a request the caller is not allowed to make
+	ForbiddenCategory ErrorCategory = "forbidden"
+)
+
+// FieldError points at the request field that made the request invalid
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
+type ErrorDomain struct {
+	err      error
+	Message  string        `json:"message"`
+	Code     string        `json:"code"`
+	Category ErrorCategory `json:"category"`
+	Fields   []FieldError  `json:"fields,omitempty"`
+}
+
 func (e ErrorDomain) Error() string {
 	return e.err.Error()
 }
 
-func NewErrorDomain(errorMessage string) error {
-	return &ErrorDomain{
-		err:     errors.New(errorMessage),
-		Message: errorMessage,
+func newErrorDomain(category ErrorCategory, code, errorMessage string, fields []FieldError) ErrorDomain {
+	return ErrorDomain{
+		err:      errors.New(errorMessage),
+		Message:  errorMessage,
+		Code:     code,
+		Category: category,
+		Fields:   fields,
 	}
 }
 
+// NewErrorDomain returns a validation error without a specific code
+func NewErrorDomain(errorMessage string) error {
+	return NewValidationError("validation_failed", errorMessage)
+}
+
+func NewValidationError(code, errorMessage string, fields ...FieldError) error {
+	e := newErrorDomain(ValidationCategory, code, errorMessage, fields)
+	return &e
+}
+
+func NewBadRequestError(code, errorMessage string, fields ...FieldError) error {
+	e := newErrorDomain(BadRequestCategory, code, errorMessage, fields)
+	return &e
+}
+
+func NewNotFoundError(code, errorMessage string) error {
+	e := newErrorDomain(NotFoundCategory, code, errorMessage, nil)
+	return &e
+}
+
+func NewInvalidTransitionError(code, errorMessage string) error {
+	e := newErrorDomain(InvalidTransitionCategory, code, errorMessage, nil)
+	return &e
+}
+
+func NewForbiddenError(code, errorMessage string) error {
+	e := newErrorDomain(ForbiddenCategory, code, errorMessage, nil)
+	return &e
+}
+
 func IsDomainError(e error) bool {
-	_, err := e.(*ErrorDomain)
-	return err
+	_, ok := AsErrorDomain(e)
+	return ok
+}
+
+// AsErrorDomain returns the domain error details of e, false when e is not a domain error.
+// Domain errors wrapped with fmt.Errorf("...: %w", err) keep their details.
+func AsErrorDomain(e error) (*ErrorDomain, bool) {
+	var domainErr *ErrorDomain
+	if errors.As(e, &domainErr) {
+		return domainErr, true
+	}
+	var conflictErr *ConflictError
+	if errors.As(e, &conflictErr) {
+		return &conflictErr.ErrorDomain, true
+	}
+	return nil, false
+}
+
+// ConflictError is the domain error returned when a write is based on an outdated version of the data
+type ConflictError struct {
+	ErrorDomain
+}
+
+func NewConflictError(code, errorMessage string) error {
+	return &ConflictError{
+		ErrorDomain: newErrorDomain(ConflictCategory, code, errorMessage, nil),
+	}
+}
+
+func IsConflictError(e error) bool {
+	var conflictErr *ConflictError
+	return errors.As(e, &conflictErr)
 }
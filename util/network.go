@@ -0,0 +1,10 @@
+package util
+
+import "net"
+
+// IsPublicIP reports whether the address can be reached from the internet, so it is not a loopback,
+// private, link-local, multicast or unspecified address
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}